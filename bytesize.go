@@ -0,0 +1,129 @@
+package configura
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes, like a buffer size or an upload limit. A Variable[ByteSize] is written with a unit,
+// e.g. 10MiB, 512KB or 1.5G, see ParseByteSize, and is formatted back the same way.
+type ByteSize uint64
+
+// Units of ByteSize. SI units are powers of 1000, IEC units powers of 1024.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+	EB ByteSize = 1000 * PB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+	EiB ByteSize = 1024 * PiB
+)
+
+// byteUnit is a unit of ByteSize, as it is written.
+type byteUnit struct {
+	name string
+	size ByteSize
+}
+
+// byteUnits are the units ByteSize values are formatted with, largest first, IEC units before SI units.
+var byteUnits = []byteUnit{
+	{"EiB", EiB}, {"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"EB", EB}, {"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+}
+
+// byteUnitSizes maps every accepted spelling of a unit, in lower case, to its size.
+var byteUnitSizes = map[string]ByteSize{
+	"": Byte, "b": Byte,
+	"k": KB, "kb": KB, "ki": KiB, "kib": KiB,
+	"m": MB, "mb": MB, "mi": MiB, "mib": MiB,
+	"g": GB, "gb": GB, "gi": GiB, "gib": GiB,
+	"t": TB, "tb": TB, "ti": TiB, "tib": TiB,
+	"p": PB, "pb": PB, "pi": PiB, "pib": PiB,
+	"e": EB, "eb": EB, "ei": EiB, "eib": EiB,
+}
+
+// ParseByteSize parses a number of bytes, written as a decimal number followed by an optional unit, e.g. 512,
+// 512B, 10MiB or 1.5G. SI units (K, M, G, T, P, E, optionally followed by B) are powers of 1000, IEC units (Ki, Mi,
+// Gi, Ti, Pi, Ei, optionally followed by B) are powers of 1024. Units are case-insensitive, and may be separated
+// from the number by spaces.
+//
+// Fractions are allowed as long as they amount to a whole number of bytes, so 1.5KiB is valid but 1.5B isn't. Sizes
+// that don't fit in a uint64 are rejected with a *strconv.NumError wrapping strconv.ErrRange.
+func ParseByteSize(s string) (ByteSize, error) {
+	number := strings.TrimSpace(s)
+	end := strings.IndexFunc(number, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end < 0 {
+		end = len(number)
+	}
+	number, unit := number[:end], strings.TrimSpace(number[end:])
+	if number == "" || number == "." || strings.Count(number, ".") > 1 {
+		return 0, &strconv.NumError{Func: "ParseByteSize", Num: s, Err: strconv.ErrSyntax}
+	}
+	size, ok := byteUnitSizes[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown byte size unit %q", unit)
+	}
+
+	n, _ := new(big.Rat).SetString(number)
+	n.Mul(n, new(big.Rat).SetUint64(uint64(size)))
+	if !n.IsInt() {
+		return 0, errors.New("byte size is not a whole number of bytes")
+	}
+	if !n.Num().IsUint64() {
+		return 0, &strconv.NumError{Func: "ParseByteSize", Num: s, Err: strconv.ErrRange}
+	}
+	return ByteSize(n.Num().Uint64()), nil
+}
+
+// String formats the size with the unit giving the shortest representation, using at most three decimals, e.g.
+// 10MiB, 512KB or 1.5GB. Sizes that no unit represents exactly are formatted in bytes, e.g. 1023B.
+func (s ByteSize) String() string {
+	best := strconv.FormatUint(uint64(s), 10) + "B"
+	for _, unit := range byteUnits {
+		if s < unit.size {
+			continue
+		}
+		n := new(big.Rat).SetFrac(new(big.Int).SetUint64(uint64(s)), new(big.Int).SetUint64(uint64(unit.size)))
+		if !new(big.Rat).Mul(n, big.NewRat(1000, 1)).IsInt() {
+			continue
+		}
+		text := strings.TrimSuffix(strings.TrimRight(n.FloatString(3), "0"), ".") + unit.name
+		if len(text) < len(best) {
+			best = text
+		}
+	}
+	return best
+}
+
+// MarshalText implements encoding.TextMarshaler, formatting the size as String does.
+func (s ByteSize) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the size with ParseByteSize.
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = ByteSize(0)
+	_ encoding.TextUnmarshaler = (*ByteSize)(nil)
+)
@@ -0,0 +1,119 @@
+package configura
+
+import (
+	"encoding/json"
+	"flag"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ByteSizeSuite tests parsing and formatting sizes written with units.
+type ByteSizeSuite struct {
+	suite.Suite
+}
+
+func TestByteSizeSuite(t *testing.T) {
+	suite.Run(t, new(ByteSizeSuite))
+}
+
+func (s *ByteSizeSuite) TestParse() {
+	tests := map[string]ByteSize{
+		"0":                    0,
+		"512":                  512,
+		"512B":                 512,
+		"512KB":                512 * KB,
+		"512kb":                512 * KB,
+		"10MiB":                10 * MiB,
+		"10 Mi":                10 * MiB,
+		"1.5G":                 1500 * MB,
+		"1.5GiB":               1536 * MiB,
+		".5K":                  500,
+		"2.TB":                 2 * TB,
+		" 3 eib ":              3 * EiB,
+		"16EB":                 16 * EB,
+		"1.0000KiB":            KiB,
+		"18446744073709551615": ByteSize(1<<64 - 1),
+	}
+	for raw, want := range tests {
+		got, err := ParseByteSize(raw)
+		s.NoError(err, raw)
+		s.Equal(want, got, raw)
+	}
+}
+
+func (s *ByteSizeSuite) TestParseErrors() {
+	for _, raw := range []string{"", "MB", ".", "1.2.3", "-1K"} {
+		_, err := ParseByteSize(raw)
+		s.ErrorIs(err, strconv.ErrSyntax, raw)
+	}
+	for _, raw := range []string{"16EiB", "18446744073709551616", "20EB", "99999999999999999999999G"} {
+		_, err := ParseByteSize(raw)
+		s.ErrorIs(err, strconv.ErrRange, raw)
+	}
+
+	_, err := ParseByteSize("10MX")
+	s.EqualError(err, `unknown byte size unit "MX"`)
+	_, err = ParseByteSize("1,5K")
+	s.EqualError(err, `unknown byte size unit ",5K"`)
+	_, err = ParseByteSize("1.5B")
+	s.EqualError(err, "byte size is not a whole number of bytes")
+}
+
+func (s *ByteSizeSuite) TestString() {
+	tests := map[ByteSize]string{
+		0:                    "0B",
+		1:                    "1B",
+		1023:                 "1023B",
+		KiB:                  "1KiB",
+		KB:                   "1KB",
+		10 * MiB:             "10MiB",
+		512 * KB:             "512KB",
+		1500 * MB:            "1.5GB",
+		1536 * MiB:           "1.5GiB",
+		1<<64 - 1:            "18446744073709551615B",
+		1001:                 "1001B",
+		1000001:              "1000001B",
+		1001 * KB:            "1001KB",
+		ByteSize(2500) * MiB: "2500MiB",
+	}
+	for size, want := range tests {
+		s.Equal(want, size.String(), uint64(size))
+		parsed, err := ParseByteSize(size.String())
+		s.Require().NoError(err)
+		s.Equal(size, parsed, "String must round-trip")
+	}
+
+	text, err := json.Marshal(map[string]ByteSize{"limit": 10 * MiB})
+	s.Require().NoError(err)
+	s.JSONEq(`{"limit": "10MiB"}`, string(text))
+}
+
+func (s *ByteSizeSuite) TestLoad() {
+	src, err := ParseJSON("config.json", strings.NewReader(`{"buffer": 4096, "upload": "1.5G", "cache": "lots"}`))
+	s.Require().NoError(err)
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{"MAX_BODY": "10MiB", "HUGE": "17EiB"}), src)
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[ByteSize]("MAX_BODY"), MiB))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[ByteSize]("BUFFER"), 0))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[ByteSize]("UPLOAD"), 0))
+	s.Equal(10*MiB, Get(cfg, Variable[ByteSize]("MAX_BODY")))
+	s.Equal(4*KiB, Get(cfg, Variable[ByteSize]("BUFFER")))
+	s.Equal(1500*MB, Get(cfg, Variable[ByteSize]("UPLOAD")))
+
+	err = LoadEnvironment(cfg, Variable[ByteSize]("HUGE"), MiB)
+	s.EqualError(err, `invalid value "17EiB" for configuration variable HUGE of type configura.ByteSize from test: value out of range`)
+	s.Equal(MiB, Get(cfg, Variable[ByteSize]("HUGE")))
+
+	err = LoadEnvironment(cfg, Variable[ByteSize]("CACHE"), MiB)
+	s.ErrorIs(err, strconv.ErrSyntax)
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal("10MiB", fs.Lookup("max-body").DefValue)
+	s.Require().NoError(fs.Parse([]string{"--max-body=512KB"}))
+	s.Equal(512*KB, Get(cfg, Variable[ByteSize]("MAX_BODY")))
+}
@@ -2,18 +2,64 @@ package configura
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"maps"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var ErrMissingVariable = errors.New("missing configuration variables")
 
-type constraint interface {
-	string | int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr | []byte | []rune | float32 | float64 | bool
-}
+// constraint documents the types configuration variables can hold:
+//
+//   - the basic types string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, []byte,
+//     []rune, float32, float64 and bool, as well as time.Time and time.Duration;
+//   - types defined on top of a basic type, e.g. type Port uint16 or type LogLevel string, which are parsed and stored
+//     like their underlying type, but kept apart from it, so a Variable[Port] and a Variable[uint16] of the same name
+//     are different variables;
+//   - types implementing encoding.TextUnmarshaler, like netip.Addr or *regexp.Regexp, which are parsed with
+//     UnmarshalText, and converted back to text with MarshalText if they implement encoding.TextMarshaler;
+//   - slices of any of the above, like []string or []netip.Addr, which are loaded from a delimited list or from one
+//     key per element, see Separator;
+//   - maps of any of the above, like map[string]int, which are loaded from a delimited list of key=value entries or
+//     from one key per entry, prefixed with the key of the variable and an underscore.
+//
+// Go can't express this as a type constraint, so LoadEnvironment, WriteConfiguration and BindFlag reject a variable
+// of any other type with a *ParseError wrapping ErrUnsupportedType, whether or not it is set.
+type constraint = any
 
 type Variable[T constraint] string
 
+// variable is implemented by every Variable[T], giving access to what depends on T without knowing it.
+type variable interface {
+	name() string
+	typeName() string
+	bindFlag(config *ConfigImpl, fs *flag.FlagSet) error
+}
+
+// name returns the name of the configuration variable.
+func (v Variable[T]) name() string {
+	return string(v)
+}
+
+// typeName returns the name of the Go type of the configuration variable, e.g. "uint8".
+func (v Variable[T]) typeName() string {
+	return reflect.TypeFor[T]().String()
+}
+
+// typeNameOf returns the Go type of the configuration variable held by key, or the type of key itself if it isn't a
+// configuration variable.
+func typeNameOf(key any) string {
+	if v, ok := key.(variable); ok {
+		return v.typeName()
+	}
+	return fmt.Sprintf("%T", key)
+}
+
 // Config is an interface that defines methods for accessing configuration variables of various types.
 type Config interface {
 	String(key Variable[string]) string
@@ -33,35 +79,17 @@ type Config interface {
 	Float32(key Variable[float32]) float32
 	Float64(key Variable[float64]) float64
 	Bool(key Variable[bool]) bool
+	Time(key Variable[time.Time]) time.Time
+	Duration(key Variable[time.Duration]) time.Duration
 	ConfigurationKeysRegistered(keys ...any) error
 }
 
-// Locks for each type of configuration variable to ensure thread-safe access.
-var (
-	stringLock  = sync.RWMutex{}
-	intLock     = sync.RWMutex{}
-	int8Lock    = sync.RWMutex{}
-	int16Lock   = sync.RWMutex{}
-	int32Lock   = sync.RWMutex{}
-	int64Lock   = sync.RWMutex{}
-	uintLock    = sync.RWMutex{}
-	uint8Lock   = sync.RWMutex{}
-	uint16Lock  = sync.RWMutex{}
-	uint32Lock  = sync.RWMutex{}
-	uint64Lock  = sync.RWMutex{}
-	uintptrLock = sync.RWMutex{}
-	bytesLock   = sync.RWMutex{}
-	runesLock   = sync.RWMutex{}
-	float32Lock = sync.RWMutex{}
-	float64Lock = sync.RWMutex{}
-	boolLock    = sync.RWMutex{}
-)
-
 // WriteConfiguration is a generic function that writes configuration values to the provided configuration struct.
 // It uses type assertions to determine the type of the values and writes them to the appropriate map in the
 // configuration struct. This function is designed to be used to Mock the configuration in tests or to set
-// default values in the configuration struct. It will overwrite any existing values for the keys provided and is
-// not meant to be used for runtime configuration changes.
+// default values in the configuration struct. It replaces every variable of type T: the variables of that type left
+// out of values are removed, and aren't brought back by Reload. The values written are kept as they are when the
+// configuration is reloaded. Use Reload for runtime configuration changes drawn from the sources.
 func WriteConfiguration[T constraint](cfg Config, values map[Variable[T]]T) error {
 	if cfg == nil {
 		return errors.New("Config cannot be nil")
@@ -71,370 +99,408 @@ func WriteConfiguration[T constraint](cfg Config, values map[Variable[T]]T) erro
 	if !ok {
 		return errors.New("invalid configuration type, expected *ConfigImpl")
 	}
-
-	switch v := any(values).(type) {
-	case map[Variable[string]]string:
-		stringLock.Lock()
-		defer stringLock.Unlock()
-		typecastCfg.regString = v
-	case map[Variable[int]]int:
-		intLock.Lock()
-		defer intLock.Unlock()
-		typecastCfg.regInt = v
-	case map[Variable[int8]]int8:
-		int8Lock.Lock()
-		defer int8Lock.Unlock()
-		typecastCfg.regInt8 = v
-	case map[Variable[int16]]int16:
-		int16Lock.Lock()
-		defer int16Lock.Unlock()
-		typecastCfg.regInt16 = v
-	case map[Variable[int32]]int32:
-		int32Lock.Lock()
-		defer int32Lock.Unlock()
-		typecastCfg.regInt32 = v
-	case map[Variable[int64]]int64:
-		int64Lock.Lock()
-		defer int64Lock.Unlock()
-		typecastCfg.regInt64 = v
-	case map[Variable[uint]]uint:
-		uintLock.Lock()
-		defer uintLock.Unlock()
-		typecastCfg.regUint = v
-	case map[Variable[uint8]]uint8:
-		uint8Lock.Lock()
-		defer uint8Lock.Unlock()
-		typecastCfg.regUint8 = v
-	case map[Variable[uint16]]uint16:
-		uint16Lock.Lock()
-		defer uint16Lock.Unlock()
-		typecastCfg.regUint16 = v
-	case map[Variable[uint32]]uint32:
-		uint32Lock.Lock()
-		defer uint32Lock.Unlock()
-		typecastCfg.regUint32 = v
-	case map[Variable[uint64]]uint64:
-		uint64Lock.Lock()
-		defer uint64Lock.Unlock()
-		typecastCfg.regUint64 = v
-	case map[Variable[uintptr]]uintptr:
-		uintptrLock.Lock()
-		defer uintptrLock.Unlock()
-		typecastCfg.regUintptr = v
-	case map[Variable[[]byte]][]byte:
-		bytesLock.Lock()
-		defer bytesLock.Unlock()
-		typecastCfg.regBytes = v
-	case map[Variable[[]rune]][]rune:
-		runesLock.Lock()
-		defer runesLock.Unlock()
-		typecastCfg.regRunes = v
-	case map[Variable[float32]]float32:
-		float32Lock.Lock()
-		defer float32Lock.Unlock()
-		typecastCfg.regFloat32 = v
-	case map[Variable[float64]]float64:
-		float64Lock.Lock()
-		defer float64Lock.Unlock()
-		typecastCfg.regFloat64 = v
-	case map[Variable[bool]]bool:
-		boolLock.Lock()
-		defer boolLock.Unlock()
-		typecastCfg.regBool = v
-	default:
-		return errors.New("unsupported values type for WriteConfiguration")
+	if err := checkSupported[T](""); err != nil {
+		if len(values) == 0 {
+			return err
+		}
+		var errs []error
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			errs = append(errs, checkSupported[T](string(key)))
+		}
+		return errors.Join(errs...)
 	}
 
-	return nil
+	return typecastCfg.update(func(r *registry) error {
+		switch v := any(values).(type) {
+		case map[Variable[string]]string:
+			r.regString = maps.Clone(v)
+		case map[Variable[int]]int:
+			r.regInt = maps.Clone(v)
+		case map[Variable[int8]]int8:
+			r.regInt8 = maps.Clone(v)
+		case map[Variable[int16]]int16:
+			r.regInt16 = maps.Clone(v)
+		case map[Variable[int32]]int32:
+			r.regInt32 = maps.Clone(v)
+		case map[Variable[int64]]int64:
+			r.regInt64 = maps.Clone(v)
+		case map[Variable[uint]]uint:
+			r.regUint = maps.Clone(v)
+		case map[Variable[uint8]]uint8:
+			r.regUint8 = maps.Clone(v)
+		case map[Variable[uint16]]uint16:
+			r.regUint16 = maps.Clone(v)
+		case map[Variable[uint32]]uint32:
+			r.regUint32 = maps.Clone(v)
+		case map[Variable[uint64]]uint64:
+			r.regUint64 = maps.Clone(v)
+		case map[Variable[uintptr]]uintptr:
+			r.regUintptr = maps.Clone(v)
+		case map[Variable[[]byte]][]byte:
+			r.regBytes = maps.Clone(v)
+		case map[Variable[[]rune]][]rune:
+			r.regRunes = maps.Clone(v)
+		case map[Variable[float32]]float32:
+			r.regFloat32 = maps.Clone(v)
+		case map[Variable[float64]]float64:
+			r.regFloat64 = maps.Clone(v)
+		case map[Variable[bool]]bool:
+			r.regBool = maps.Clone(v)
+		case map[Variable[time.Time]]time.Time:
+			r.regTime = maps.Clone(v)
+		case map[Variable[time.Duration]]time.Duration:
+			r.regDuration = maps.Clone(v)
+		default:
+			// Variables of types defined by the user share a map, where only those of type T are replaced.
+			other := make(map[any]any, len(r.regOther)+len(values))
+			for key, value := range r.regOther {
+				if _, ok := key.(Variable[T]); !ok {
+					other[key] = value
+				}
+			}
+			for key, value := range values {
+				other[key] = value
+			}
+			r.regOther = other
+		}
+
+		// Every variable of type T is replaced, so none of them is reloaded from the sources any longer, including
+		// those left out of values, which are removed.
+		r.origins = withoutType[T](r.origins)
+		r.secrets = withoutType[T](r.secrets)
+		r.bindings = withoutType[T](r.bindings)
+		return nil
+	})
+}
+
+// withoutType returns a copy of m without the entries of the configuration variables of type T.
+func withoutType[T constraint, V any](m map[any]V) map[any]V {
+	next := maps.Clone(m)
+	maps.DeleteFunc(next, func(key any, _ V) bool {
+		_, ok := key.(Variable[T])
+		return ok
+	})
+	return next
 }
 
 // LoadEnvironment is a generic function that loads an environment variable into the provided configuration,
 // using the specified key and fallback value. It uses type assertions to determine the type of the key
 // and fallback value, and registers the variable in the appropriate map of the configuration struct.
-func LoadEnvironment[T constraint](config *ConfigImpl, key Variable[T], fallback T) {
-	switch any(key).(type) {
-	case Variable[string]:
-		stringLock.Lock()
-		defer stringLock.Unlock()
-		config.regString[any(key).(Variable[string])] = String(any(key).(Variable[string]), any(fallback).(string))
-	case Variable[int]:
-		intLock.Lock()
-		defer intLock.Unlock()
-		config.regInt[any(key).(Variable[int])] = Int(any(key).(Variable[int]), any(fallback).(int))
-	case Variable[int8]:
-		int8Lock.Lock()
-		defer int8Lock.Unlock()
-		config.regInt8[any(key).(Variable[int8])] = Int8(any(key).(Variable[int8]), any(fallback).(int8))
-	case Variable[int16]:
-		int16Lock.Lock()
-		defer int16Lock.Unlock()
-		config.regInt16[any(key).(Variable[int16])] = Int16(any(key).(Variable[int16]), any(fallback).(int16))
-	case Variable[int32]:
-		int32Lock.Lock()
-		defer int32Lock.Unlock()
-		config.regInt32[any(key).(Variable[int32])] = Int32(any(key).(Variable[int32]), any(fallback).(int32))
-	case Variable[int64]:
-		int64Lock.Lock()
-		defer int64Lock.Unlock()
-		config.regInt64[any(key).(Variable[int64])] = Int64(any(key).(Variable[int64]), any(fallback).(int64))
-	case Variable[uint]:
-		uintLock.Lock()
-		defer uintLock.Unlock()
-		config.regUint[any(key).(Variable[uint])] = Uint(any(key).(Variable[uint]), any(fallback).(uint))
-	case Variable[uint8]:
-		uint8Lock.Lock()
-		defer uint8Lock.Unlock()
-		config.regUint8[any(key).(Variable[uint8])] = Uint8(any(key).(Variable[uint8]), any(fallback).(uint8))
-	case Variable[uint16]:
-		uint16Lock.Lock()
-		defer uint16Lock.Unlock()
-		config.regUint16[any(key).(Variable[uint16])] = Uint16(any(key).(Variable[uint16]), any(fallback).(uint16))
-	case Variable[uint32]:
-		uint32Lock.Lock()
-		defer uint32Lock.Unlock()
-		config.regUint32[any(key).(Variable[uint32])] = Uint32(any(key).(Variable[uint32]), any(fallback).(uint32))
-	case Variable[uint64]:
-		uint64Lock.Lock()
-		defer uint64Lock.Unlock()
-		config.regUint64[any(key).(Variable[uint64])] = Uint64(any(key).(Variable[uint64]), any(fallback).(uint64))
-	case Variable[uintptr]:
-		uintptrLock.Lock()
-		defer uintptrLock.Unlock()
-		config.regUintptr[any(key).(Variable[uintptr])] = Uintptr(any(key).(Variable[uintptr]), any(fallback).(uintptr))
-	case Variable[[]byte]:
-		bytesLock.Lock()
-		defer bytesLock.Unlock()
-		config.regBytes[any(key).(Variable[[]byte])] = Bytes(any(key).(Variable[[]byte]), any(fallback).([]byte))
-	case Variable[[]rune]:
-		runesLock.Lock()
-		defer runesLock.Unlock()
-		config.regRunes[any(key).(Variable[[]rune])] = Runes(any(key).(Variable[[]rune]), any(fallback).([]rune))
-	case Variable[float32]:
-		float32Lock.Lock()
-		defer float32Lock.Unlock()
-		config.regFloat32[any(key).(Variable[float32])] = Float32(any(key).(Variable[float32]), any(fallback).(float32))
-	case Variable[float64]:
-		float64Lock.Lock()
-		defer float64Lock.Unlock()
-		config.regFloat64[any(key).(Variable[float64])] = Float64(any(key).(Variable[float64]), any(fallback).(float64))
-	case Variable[bool]:
-		boolLock.Lock()
-		defer boolLock.Unlock()
-		config.regBool[any(key).(Variable[bool])] = Bool(any(key).(Variable[bool]), any(fallback).(bool))
+//
+// Values are drawn from the sources of the configuration, which are the environment variables unless changed with
+// SetSources. The source that supplied the value is recorded, see Origin.
+//
+// If the environment variable is set but can't be parsed into T, the fallback value is registered and a *ParseError
+// is returned. Callers that want strict loading should check the error, or load their variables with LoadAll, which
+// reports the errors of every load at once.
+//
+// The options customize how the value is parsed, e.g. DurationUnit or TimeLayouts. They are kept for reloads, and
+// apply to the command-line flag of the variable too, see BindFlag.
+func LoadEnvironment[T constraint](config *ConfigImpl, key Variable[T], fallback T, opts ...LoadOption) error {
+	if err := checkSupported[T](string(key)); err != nil {
+		return err
+	}
+	b := variableBinding[T]{key: key, fallback: fallback, opts: newLoadOptions(opts)}
+	var err error
+	_ = config.update(func(r *registry) error {
+		err = b.load(r)
+		r.bindings = with(r.bindings, any(key), binding(b))
+		return nil
+	})
+	return err
+}
+
+// Loader loads a configuration variable into config, see Load and LoadAll.
+type Loader func(config *ConfigImpl) error
+
+// Load returns a Loader that loads the variable with LoadEnvironment, using the fallback value and options.
+func Load[T constraint](key Variable[T], fallback T, opts ...LoadOption) Loader {
+	return func(config *ConfigImpl) error {
+		return LoadEnvironment(config, key, fallback, opts...)
+	}
+}
+
+// LoadAll runs every loader in order. It doesn't stop at the first failure, so that every invalid value is reported
+// at once: the errors are collected in a *LoadError, whose ParseErrors method returns each *ParseError. It returns
+// nil if every load succeeded.
+func LoadAll(config *ConfigImpl, loads ...Loader) error {
+	var errs []error
+	for _, load := range loads {
+		if err := load(config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
 	}
+	return &LoadError{Errs: errs}
 }
 
 // ConfigImpl is a concrete implementation of the Config interface, holding maps for each type of configuration
 // variable. It provides methods to retrieve values for each type and checks if all required keys are registered.
+//
+// The maps are kept in an immutable snapshot that is swapped atomically on every write. Reads never block and a
+// single read always observes one consistent snapshot across all types, even while writes are in progress.
 type ConfigImpl struct {
-	regString  map[Variable[string]]string
-	regInt     map[Variable[int]]int
-	regInt8    map[Variable[int8]]int8
-	regInt16   map[Variable[int16]]int16
-	regInt32   map[Variable[int32]]int32
-	regInt64   map[Variable[int64]]int64
-	regUint    map[Variable[uint]]uint
-	regUint8   map[Variable[uint8]]uint8
-	regUint16  map[Variable[uint16]]uint16
-	regUint32  map[Variable[uint32]]uint32
-	regUint64  map[Variable[uint64]]uint64
-	regUintptr map[Variable[uintptr]]uintptr
-	regBytes   map[Variable[[]byte]][]byte
-	regRunes   map[Variable[[]rune]][]rune
-	regFloat32 map[Variable[float32]]float32
-	regFloat64 map[Variable[float64]]float64
-	regBool    map[Variable[bool]]bool
+	// mu serializes writers. Every instance owns its own lock, so unrelated configurations never contend, and
+	// readers never take it at all.
+	mu       sync.Mutex
+	snapshot atomic.Pointer[registry]
+
+	// history holds the configurations replaced by reloads, oldest first, see Rollback. It is guarded by mu.
+	history      []*registry
+	historyLimit int
+
+	// pending holds the notifications of published changes that haven't been delivered yet, in publish order, and
+	// delivering is set while a goroutine delivers them, see deliver. Both are guarded by mu.
+	pending    []func()
+	delivering bool
+
+	// listenMu guards the subscribers to changes, the listeners of reloads and the validators.
+	listenMu        sync.Mutex
+	subscribers     []*subscriber
+	reloadListeners []*func(ReloadEvent)
+	validators      []*func(Config) error
 }
 
 func NewConfigImpl() *ConfigImpl {
-	return &ConfigImpl{
-		regString:  make(map[Variable[string]]string),
-		regInt:     make(map[Variable[int]]int),
-		regInt8:    make(map[Variable[int8]]int8),
-		regInt16:   make(map[Variable[int16]]int16),
-		regInt32:   make(map[Variable[int32]]int32),
-		regInt64:   make(map[Variable[int64]]int64),
-		regUint:    make(map[Variable[uint]]uint),
-		regUint8:   make(map[Variable[uint8]]uint8),
-		regUint16:  make(map[Variable[uint16]]uint16),
-		regUint32:  make(map[Variable[uint32]]uint32),
-		regUint64:  make(map[Variable[uint64]]uint64),
-		regUintptr: make(map[Variable[uintptr]]uintptr),
-		regBytes:   make(map[Variable[[]byte]][]byte),
-		regRunes:   make(map[Variable[[]rune]][]rune),
-		regFloat32: make(map[Variable[float32]]float32),
-		regFloat64: make(map[Variable[float64]]float64),
-		regBool:    make(map[Variable[bool]]bool),
+	c := &ConfigImpl{historyLimit: DefaultRollbackHistory}
+	c.snapshot.Store(newRegistry())
+	return c
+}
+
+// load returns the currently published snapshot of the configuration. The returned registry must not be modified.
+func (c *ConfigImpl) load() *registry {
+	if r := c.snapshot.Load(); r != nil {
+		return r
+	}
+	return emptyRegistry
+}
+
+// update builds a new snapshot by applying fn to a shallow copy of the current one, and publishes it if fn succeeds.
+// The maps of the copy are shared with the published snapshot, so fn must replace any map it changes rather than
+// modify it in place. Writers are serialized, so no update is lost to a concurrent one. Subscribers are notified
+// once the snapshot is published, outside of the lock, see deliver.
+func (c *ConfigImpl) update(fn func(r *registry) error) error {
+	return c.updateThen(fn, nil)
+}
+
+// updateThen is update, calling then with the outcome of fn after the subscribers have been notified of it. then is
+// queued with the notifications, so it observes the outcomes of concurrent updates in the order they were made.
+func (c *ConfigImpl) updateThen(fn func(r *registry) error, then func(err error)) error {
+	err := c.publish(fn, then)
+	c.deliver()
+	return err
+}
+
+// publish applies fn to a copy of the current snapshot and publishes the result, see update. The notification of the
+// subscribers and then, if not nil, are queued for deliver while the lock is still held.
+func (c *ConfigImpl) publish(fn func(r *registry) error, then func(err error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.load()
+	copied := *prev
+	err := fn(&copied)
+	if err == nil {
+		c.snapshot.Store(&copied)
+		c.pending = append(c.pending, func() { c.notify(prev, &copied) })
+	}
+	if then != nil {
+		c.pending = append(c.pending, func() { then(err) })
 	}
+	return err
+}
+
+// deliver delivers the pending notifications one at a time, in the order the changes were published. Only one
+// goroutine delivers them at a time: a change published meanwhile, by another goroutine or by a subscriber changing
+// the configuration itself, is delivered by the goroutine already delivering, once the current notification returns.
+func (c *ConfigImpl) deliver() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.delivering {
+		return
+	}
+	c.delivering = true
+	defer func() { c.delivering = false }()
+
+	for len(c.pending) > 0 {
+		next := c.pending[0]
+		c.pending = c.pending[1:]
+		c.mu.Unlock()
+		func() {
+			defer c.mu.Lock()
+			next()
+		}()
+	}
+}
+
+// Snapshot returns a read-only view of the configuration as it is right now. Writes made to c afterwards are not
+// visible through the returned Config, which makes it useful to read several related values consistently.
+func (c *ConfigImpl) Snapshot() Config {
+	frozen := &ConfigImpl{}
+	frozen.snapshot.Store(c.load())
+	return frozen
 }
 
 var _ Config = (*ConfigImpl)(nil)
 
 func (c *ConfigImpl) String(key Variable[string]) string {
-	stringLock.RLock()
-	defer stringLock.RUnlock()
-	if value, exists := c.regString[key]; exists {
+	if value, exists := c.load().regString[key]; exists {
 		return value
 	}
 	return ""
 }
 
 func (c *ConfigImpl) Int(key Variable[int]) int {
-	intLock.RLock()
-	defer intLock.RUnlock()
-	if value, exists := c.regInt[key]; exists {
+	if value, exists := c.load().regInt[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Int8(key Variable[int8]) int8 {
-	int8Lock.RLock()
-	defer int8Lock.RUnlock()
-	if value, exists := c.regInt8[key]; exists {
+	if value, exists := c.load().regInt8[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Int16(key Variable[int16]) int16 {
-	int16Lock.RLock()
-	defer int16Lock.RUnlock()
-	if value, exists := c.regInt16[key]; exists {
+	if value, exists := c.load().regInt16[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Int32(key Variable[int32]) int32 {
-	int32Lock.RLock()
-	defer int32Lock.RUnlock()
-	if value, exists := c.regInt32[key]; exists {
+	if value, exists := c.load().regInt32[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Int64(key Variable[int64]) int64 {
-	int64Lock.RLock()
-	defer int64Lock.RUnlock()
-	if value, exists := c.regInt64[key]; exists {
+	if value, exists := c.load().regInt64[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uint(key Variable[uint]) uint {
-	uintLock.RLock()
-	defer uintLock.RUnlock()
-	if value, exists := c.regUint[key]; exists {
+	if value, exists := c.load().regUint[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uint8(key Variable[uint8]) uint8 {
-	uint8Lock.RLock()
-	defer uint8Lock.RUnlock()
-	if value, exists := c.regUint8[key]; exists {
+	if value, exists := c.load().regUint8[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uint16(key Variable[uint16]) uint16 {
-	uint16Lock.RLock()
-	defer uint16Lock.RUnlock()
-	if value, exists := c.regUint16[key]; exists {
+	if value, exists := c.load().regUint16[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uint32(key Variable[uint32]) uint32 {
-	uint32Lock.RLock()
-	defer uint32Lock.RUnlock()
-	if value, exists := c.regUint32[key]; exists {
+	if value, exists := c.load().regUint32[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uint64(key Variable[uint64]) uint64 {
-	uint64Lock.RLock()
-	defer uint64Lock.RUnlock()
-	if value, exists := c.regUint64[key]; exists {
+	if value, exists := c.load().regUint64[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Uintptr(key Variable[uintptr]) uintptr {
-	uintptrLock.RLock()
-	defer uintptrLock.RUnlock()
-	if value, exists := c.regUintptr[key]; exists {
+	if value, exists := c.load().regUintptr[key]; exists {
 		return value
 	}
 	return 0
 }
 
 func (c *ConfigImpl) Bytes(key Variable[[]byte]) []byte {
-	bytesLock.RLock()
-	defer bytesLock.RUnlock()
-	if value, exists := c.regBytes[key]; exists {
+	if value, exists := c.load().regBytes[key]; exists {
 		return value
 	}
 	return nil
 }
 
 func (c *ConfigImpl) Runes(key Variable[[]rune]) []rune {
-	runesLock.RLock()
-	defer runesLock.RUnlock()
-	if value, exists := c.regRunes[key]; exists {
+	if value, exists := c.load().regRunes[key]; exists {
 		return value
 	}
 	return nil
 }
 
 func (c *ConfigImpl) Float32(key Variable[float32]) float32 {
-	float32Lock.RLock()
-	defer float32Lock.RUnlock()
-	if value, exists := c.regFloat32[key]; exists {
+	if value, exists := c.load().regFloat32[key]; exists {
 		return value
 	}
 	return 0.0
 }
 
 func (c *ConfigImpl) Float64(key Variable[float64]) float64 {
-	float64Lock.RLock()
-	defer float64Lock.RUnlock()
-	if value, exists := c.regFloat64[key]; exists {
+	if value, exists := c.load().regFloat64[key]; exists {
 		return value
 	}
 	return 0.0
 }
 
 func (c *ConfigImpl) Bool(key Variable[bool]) bool {
-	boolLock.RLock()
-	defer boolLock.RUnlock()
-	if value, exists := c.regBool[key]; exists {
+	if value, exists := c.load().regBool[key]; exists {
 		return value
 	}
 	return false
 }
 
-// missingVariableError is an error type that holds a list of missing configuration variable keys.
-type missingVariableError struct {
+func (c *ConfigImpl) Time(key Variable[time.Time]) time.Time {
+	if value, exists := c.load().regTime[key]; exists {
+		return value
+	}
+	return time.Time{}
+}
+
+func (c *ConfigImpl) Duration(key Variable[time.Duration]) time.Duration {
+	if value, exists := c.load().regDuration[key]; exists {
+		return value
+	}
+	return 0
+}
+
+// MissingVariableError is returned by ConfigurationKeysRegistered when one or more configuration variables are not
+// registered. It unwraps to ErrMissingVariable, and can be retrieved with errors.As to inspect which variables are
+// missing and what type each of them is expected to have.
+type MissingVariableError struct {
+	// Keys holds the names of the missing configuration variables, in the order they were checked.
 	Keys []string
+	// Variables holds the name and expected type of each missing configuration variable, in the same order as Keys.
+	Variables []MissingVariable
 }
 
-// Error implements the error interface for missingVariableError.
-func (e missingVariableError) Error() string {
+// MissingVariable describes a single configuration variable that is not registered.
+type MissingVariable struct {
+	Key  string // Name of the configuration variable, e.g. "DATABASE_URL".
+	Type string // Go type of the configuration variable, e.g. "string".
+}
+
+// Error implements the error interface for MissingVariableError.
+func (e MissingVariableError) Error() string {
 	return "missing configuration variables: " + formatKeys(e.Keys)
 }
 
 // Unwrap implements the Unwrap method for the error interface, allowing the error to be unwrapped to ErrMissingVariable.
-func (e missingVariableError) Unwrap() error {
+func (e MissingVariableError) Unwrap() error {
 	return ErrMissingVariable
 }
 
@@ -453,99 +519,79 @@ func formatKeys(keys []string) string {
 	return result
 }
 
-var _ error = (*missingVariableError)(nil)
+var _ error = MissingVariableError{}
 
 // checkKey checks if the provided key exists in the configuration. It uses type assertion to determine the type of the
 // key and checks the corresponding map in the configuration struct.
 func (c *ConfigImpl) checkKey(key any) (string, bool) {
+	return c.load().checkKey(key)
+}
+
+// checkKey checks if the provided key exists in the registry, see ConfigImpl.checkKey.
+func (r *registry) checkKey(key any) (string, bool) {
 	var exists bool
 	var keyName string
 	switch k := key.(type) {
 	case Variable[string]:
-		stringLock.RLock()
-		defer stringLock.RUnlock()
-		_, exists = c.regString[k]
+		_, exists = r.regString[k]
 		keyName = string(k)
 	case Variable[int]:
-		intLock.RLock()
-		defer intLock.RUnlock()
-		_, exists = c.regInt[k]
+		_, exists = r.regInt[k]
 		keyName = string(k)
 	case Variable[int8]:
-		int8Lock.RLock()
-		defer int8Lock.RUnlock()
-		_, exists = c.regInt8[k]
+		_, exists = r.regInt8[k]
 		keyName = string(k)
 	case Variable[int16]:
-		int16Lock.RLock()
-		defer int16Lock.RUnlock()
-		_, exists = c.regInt16[k]
+		_, exists = r.regInt16[k]
 		keyName = string(k)
 	case Variable[int32]:
-		int32Lock.RLock()
-		defer int32Lock.RUnlock()
-		_, exists = c.regInt32[k]
+		_, exists = r.regInt32[k]
 		keyName = string(k)
 	case Variable[int64]:
-		int64Lock.RLock()
-		defer int64Lock.RUnlock()
-		_, exists = c.regInt64[k]
+		_, exists = r.regInt64[k]
 		keyName = string(k)
 	case Variable[uint]:
-		uintLock.RLock()
-		defer uintLock.RUnlock()
-		_, exists = c.regUint[k]
+		_, exists = r.regUint[k]
 		keyName = string(k)
 	case Variable[uint8]:
-		uint8Lock.RLock()
-		defer uint8Lock.RUnlock()
-		_, exists = c.regUint8[k]
+		_, exists = r.regUint8[k]
 		keyName = string(k)
 	case Variable[uint16]:
-		uint16Lock.RLock()
-		defer uint16Lock.RUnlock()
-		_, exists = c.regUint16[k]
+		_, exists = r.regUint16[k]
 		keyName = string(k)
 	case Variable[uint32]:
-		uint32Lock.RLock()
-		defer uint32Lock.RUnlock()
-		_, exists = c.regUint32[k]
+		_, exists = r.regUint32[k]
 		keyName = string(k)
 	case Variable[uint64]:
-		uint64Lock.RLock()
-		defer uint64Lock.RUnlock()
-		_, exists = c.regUint64[k]
+		_, exists = r.regUint64[k]
 		keyName = string(k)
 	case Variable[uintptr]:
-		uintptrLock.RLock()
-		defer uintptrLock.RUnlock()
-		_, exists = c.regUintptr[k]
+		_, exists = r.regUintptr[k]
 		keyName = string(k)
 	case Variable[[]byte]:
-		bytesLock.RLock()
-		defer bytesLock.RUnlock()
-		_, exists = c.regBytes[k]
+		_, exists = r.regBytes[k]
 		keyName = string(k)
 	case Variable[[]rune]:
-		runesLock.RLock()
-		defer runesLock.RUnlock()
-		_, exists = c.regRunes[k]
+		_, exists = r.regRunes[k]
 		keyName = string(k)
 	case Variable[float32]:
-		float32Lock.RLock()
-		defer float32Lock.RUnlock()
-		_, exists = c.regFloat32[k]
+		_, exists = r.regFloat32[k]
 		keyName = string(k)
 	case Variable[float64]:
-		float64Lock.RLock()
-		defer float64Lock.RUnlock()
-		_, exists = c.regFloat64[k]
+		_, exists = r.regFloat64[k]
 		keyName = string(k)
 	case Variable[bool]:
-		boolLock.RLock()
-		defer boolLock.RUnlock()
-		_, exists = c.regBool[k]
+		_, exists = r.regBool[k]
+		keyName = string(k)
+	case Variable[time.Time]:
+		_, exists = r.regTime[k]
 		keyName = string(k)
+	case Variable[time.Duration]:
+		_, exists = r.regDuration[k]
+		keyName = string(k)
+	case variable:
+		_, exists = r.regOther[k]
+		keyName = k.name()
 	}
 
 	return keyName, exists
@@ -554,15 +600,16 @@ func (c *ConfigImpl) checkKey(key any) (string, bool) {
 // ConfigurationKeysRegistered checks if all provided keys are registered in the configuration. To ensure that the
 // client of the package have taken all required keys into consideration when building the configuration object.
 func (c *ConfigImpl) ConfigurationKeysRegistered(keys ...any) error {
-	var missingKeys []string
+	var missing MissingVariableError
 	for _, key := range keys {
 		if keyName, ok := c.checkKey(key); !ok {
-			missingKeys = append(missingKeys, keyName)
+			missing.Keys = append(missing.Keys, keyName)
+			missing.Variables = append(missing.Variables, MissingVariable{Key: keyName, Type: typeNameOf(key)})
 		}
 	}
 
-	if len(missingKeys) > 0 {
-		return missingVariableError{Keys: missingKeys}
+	if len(missing.Keys) > 0 {
+		return missing
 	}
 
 	return nil
@@ -579,68 +626,42 @@ func Fallback[T comparable](value T, fallback T) T {
 }
 
 // Merge combines multiple Config instances into a single Config instance.
-// To ensure a consistent view of the source configurations, it locks all
-// configuration types for reading during the merge operation.
+// Each source configuration is read from a single snapshot, so the merge sees a consistent view of every source
+// without locking any of them.
 func Merge(cfgs ...Config) Config {
-	stringLock.RLock()
-	defer stringLock.RUnlock()
-	intLock.RLock()
-	defer intLock.RUnlock()
-	int8Lock.RLock()
-	defer int8Lock.RUnlock()
-	int16Lock.RLock()
-	defer int16Lock.RUnlock()
-	int32Lock.RLock()
-	defer int32Lock.RUnlock()
-	int64Lock.RLock()
-	defer int64Lock.RUnlock()
-	uintLock.RLock()
-	defer uintLock.RUnlock()
-	uint8Lock.RLock()
-	defer uint8Lock.RUnlock()
-	uint16Lock.RLock()
-	defer uint16Lock.RUnlock()
-	uint32Lock.RLock()
-	defer uint32Lock.RUnlock()
-	uint64Lock.RLock()
-	defer uint64Lock.RUnlock()
-	uintptrLock.RLock()
-	defer uintptrLock.RUnlock()
-	bytesLock.RLock()
-	defer bytesLock.RUnlock()
-	runesLock.RLock()
-	defer runesLock.RUnlock()
-	float32Lock.RLock()
-	defer float32Lock.RUnlock()
-	float64Lock.RLock()
-	defer float64Lock.RUnlock()
-	boolLock.RLock()
-	defer boolLock.RUnlock()
-
-	merged := NewConfigImpl()
+	merged := newRegistry()
 
 	for _, cfg := range cfgs {
 		if c, ok := cfg.(*ConfigImpl); ok {
-			maps.Copy(merged.regString, c.regString)
-			maps.Copy(merged.regInt, c.regInt)
-			maps.Copy(merged.regInt8, c.regInt8)
-			maps.Copy(merged.regInt16, c.regInt16)
-			maps.Copy(merged.regInt32, c.regInt32)
-			maps.Copy(merged.regInt64, c.regInt64)
-			maps.Copy(merged.regUint, c.regUint)
-			maps.Copy(merged.regUint8, c.regUint8)
-			maps.Copy(merged.regUint16, c.regUint16)
-			maps.Copy(merged.regUint32, c.regUint32)
-			maps.Copy(merged.regUint64, c.regUint64)
-			maps.Copy(merged.regUintptr, c.regUintptr)
-			maps.Copy(merged.regBytes, c.regBytes)
-			maps.Copy(merged.regRunes, c.regRunes)
-			maps.Copy(merged.regFloat32, c.regFloat32)
-			maps.Copy(merged.regFloat64, c.regFloat64)
-			maps.Copy(merged.regBool, c.regBool)
+			r := c.load()
+			maps.Copy(merged.regString, r.regString)
+			maps.Copy(merged.regInt, r.regInt)
+			maps.Copy(merged.regInt8, r.regInt8)
+			maps.Copy(merged.regInt16, r.regInt16)
+			maps.Copy(merged.regInt32, r.regInt32)
+			maps.Copy(merged.regInt64, r.regInt64)
+			maps.Copy(merged.regUint, r.regUint)
+			maps.Copy(merged.regUint8, r.regUint8)
+			maps.Copy(merged.regUint16, r.regUint16)
+			maps.Copy(merged.regUint32, r.regUint32)
+			maps.Copy(merged.regUint64, r.regUint64)
+			maps.Copy(merged.regUintptr, r.regUintptr)
+			maps.Copy(merged.regBytes, r.regBytes)
+			maps.Copy(merged.regRunes, r.regRunes)
+			maps.Copy(merged.regFloat32, r.regFloat32)
+			maps.Copy(merged.regFloat64, r.regFloat64)
+			maps.Copy(merged.regBool, r.regBool)
+			maps.Copy(merged.regTime, r.regTime)
+			maps.Copy(merged.regDuration, r.regDuration)
+			maps.Copy(merged.regOther, r.regOther)
+			maps.Copy(merged.origins, r.origins)
+			maps.Copy(merged.secrets, r.secrets)
 		} else {
 			panic("unsupported config type")
 		}
 	}
-	return merged
+
+	cfg := &ConfigImpl{}
+	cfg.snapshot.Store(merged)
+	return cfg
 }
@@ -0,0 +1,131 @@
+package configura
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+const benchmarkKeys = 64
+
+// newBenchmarkConfig returns a configuration populated with benchmarkKeys string and int variables.
+func newBenchmarkConfig() *ConfigImpl {
+	cfg := NewConfigImpl()
+	strValues := make(map[Variable[string]]string, benchmarkKeys)
+	intValues := make(map[Variable[int]]int, benchmarkKeys)
+	for i := 0; i < benchmarkKeys; i++ {
+		strValues[Variable[string](fmt.Sprintf("KEY_%d", i))] = "value"
+		intValues[Variable[int](fmt.Sprintf("KEY_%d", i))] = i
+	}
+	_ = WriteConfiguration(cfg, strValues)
+	_ = WriteConfiguration(cfg, intValues)
+	return cfg
+}
+
+// startWriter continuously rewrites the string values of cfg until the returned stop function is called. It is used
+// to put pressure on a configuration instance other than the one being benchmarked.
+func startWriter(cfg *ConfigImpl) (stop func()) {
+	var done atomic.Bool
+	finished := make(chan struct{})
+	values := map[Variable[string]]string{"KEY_0": "value"}
+	go func() {
+		defer close(finished)
+		for !done.Load() {
+			_ = WriteConfiguration(cfg, values)
+		}
+	}()
+	return func() {
+		done.Store(true)
+		<-finished
+	}
+}
+
+// BenchmarkReadSharedInstance measures parallel reads of a single configuration instance.
+func BenchmarkReadSharedInstance(b *testing.B) {
+	cfg := newBenchmarkConfig()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = cfg.String("KEY_1")
+		}
+	})
+}
+
+// BenchmarkReadIndependentInstances measures parallel reads where every goroutine owns its configuration instance.
+func BenchmarkReadIndependentInstances(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		cfg := newBenchmarkConfig()
+		for pb.Next() {
+			_ = cfg.String("KEY_1")
+		}
+	})
+}
+
+// BenchmarkReadWhileOtherInstanceWrites measures reads of one instance while another instance is being written to
+// continuously. Since locks are owned per instance, the writer must not slow down the readers.
+func BenchmarkReadWhileOtherInstanceWrites(b *testing.B) {
+	cfg := newBenchmarkConfig()
+	stop := startWriter(newBenchmarkConfig())
+	defer stop()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = cfg.String("KEY_1")
+			_ = cfg.Int("KEY_1")
+		}
+	})
+}
+
+// BenchmarkReadWhileSameInstanceWrites measures reads of an instance that is being written to continuously. Reads are
+// served from an immutable snapshot and never wait for the writer.
+func BenchmarkReadWhileSameInstanceWrites(b *testing.B) {
+	cfg := newBenchmarkConfig()
+	stop := startWriter(cfg)
+	defer stop()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = cfg.String("KEY_1")
+			_ = cfg.Int("KEY_1")
+		}
+	})
+}
+
+// BenchmarkWriteIndependentInstances measures parallel writes where every goroutine owns its configuration instance.
+func BenchmarkWriteIndependentInstances(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		cfg := NewConfigImpl()
+		values := map[Variable[int]]int{"KEY_0": 1}
+		for pb.Next() {
+			_ = WriteConfiguration(cfg, values)
+		}
+	})
+}
+
+// BenchmarkLoadEnvironmentIndependentInstances measures parallel environment loading into separate instances.
+func BenchmarkLoadEnvironmentIndependentInstances(b *testing.B) {
+	b.Setenv("BENCHMARK_LOAD_KEY", "42")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		cfg := NewConfigImpl()
+		for pb.Next() {
+			LoadEnvironment(cfg, Variable[int]("BENCHMARK_LOAD_KEY"), 0)
+		}
+	})
+}
+
+// BenchmarkMergeWhileOtherInstanceWrites measures Merge of two instances while an unrelated instance is being
+// written to continuously.
+func BenchmarkMergeWhileOtherInstanceWrites(b *testing.B) {
+	cfg1 := newBenchmarkConfig()
+	cfg2 := newBenchmarkConfig()
+	stop := startWriter(newBenchmarkConfig())
+	defer stop()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Merge(cfg1, cfg2)
+	}
+}
@@ -2,11 +2,18 @@ package configura
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -46,6 +53,14 @@ type FallbackSuite struct {
 }
 
 // MergeSuite tests the Merge function.
+type NamedTypeSuite struct {
+	suite.Suite
+}
+
+type TextUnmarshalerSuite struct {
+	suite.Suite
+}
+
 type MergeSuite struct {
 	suite.Suite
 }
@@ -252,6 +267,58 @@ func (s *ConfigSuite) TestBool() {
 	})
 }
 
+func (s *ConfigSuite) TestTime() {
+	key := Variable[time.Time]("TEST_TIME")
+	s.Run("KeyNotExists", func() {
+		assert.True(s.T(), s.config.Time(key).IsZero())
+	})
+	s.Run("KeyExists", func() {
+		value := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+		WriteConfiguration(s.config, map[Variable[time.Time]]time.Time{key: value})
+		assert.Equal(s.T(), value, s.config.Time(key))
+	})
+}
+
+func (s *ConfigSuite) TestDuration() {
+	key := Variable[time.Duration]("TEST_DURATION")
+	s.Run("KeyNotExists", func() {
+		assert.Zero(s.T(), s.config.Duration(key))
+	})
+	s.Run("KeyExists", func() {
+		WriteConfiguration(s.config, map[Variable[time.Duration]]time.Duration{key: 5 * time.Second})
+		assert.Equal(s.T(), 5*time.Second, s.config.Duration(key))
+	})
+}
+
+func (s *ConfigSuite) TestSnapshot() {
+	key := Variable[string]("TEST_SNAPSHOT")
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{key: "before"}))
+
+	snapshot := s.config.Snapshot()
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{key: "after"}))
+
+	assert.Equal(s.T(), "before", snapshot.String(key))
+	assert.Equal(s.T(), "after", s.config.String(key))
+}
+
+func (s *ConfigSuite) TestWriteConfigurationCopiesValues() {
+	key := Variable[int]("TEST_COPY")
+	values := map[Variable[int]]int{key: 1}
+	s.Require().NoError(WriteConfiguration(s.config, values))
+
+	// Modifying the map after it was written must not alter the published configuration.
+	values[key] = 2
+	assert.Equal(s.T(), 1, s.config.Int(key))
+}
+
+func (s *ConfigSuite) TestZeroValueConfigImpl() {
+	var cfg ConfigImpl
+	assert.Equal(s.T(), "", cfg.String("TEST_ZERO"))
+
+	LoadEnvironment(&cfg, Variable[string]("TEST_ZERO"), "fallback")
+	assert.Equal(s.T(), "fallback", cfg.String("TEST_ZERO"))
+}
+
 // --- Test Methods for LoadEnvironmentSuite ---
 
 func (s *LoadEnvironmentSuite) setEnvVar(key string, value string) {
@@ -691,6 +758,221 @@ func (s *LoadEnvironmentSuite) TestLoadFloat64() {
 	})
 }
 
+func (s *LoadEnvironmentSuite) TestLoadTime() {
+	key := Variable[time.Time]("ENV_TIME")
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("EnvVarSetValid", func() {
+		s.setEnvVar(string(key), "2024-05-01T12:30:00Z")
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback))
+		assert.Equal(s.T(), time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), cfg.Time(key).UTC())
+	})
+	s.Run("EnvVarNotSet", func() {
+		s.unsetEnvVar(string(key))
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback))
+		assert.Equal(s.T(), fallback, cfg.Time(key))
+	})
+	s.Run("EnvVarSetInvalid", func() {
+		s.setEnvVar(string(key), "not-a-time")
+		cfg := NewConfigImpl()
+		err := LoadEnvironment(cfg, key, fallback)
+		var parseErr *ParseError
+		s.Require().ErrorAs(err, &parseErr)
+		s.Equal("time.Time", parseErr.Type)
+		s.ErrorContains(err, "not an RFC 3339 timestamp, local date or local time")
+		assert.Equal(s.T(), fallback, cfg.Time(key))
+	})
+}
+
+func (s *LoadEnvironmentSuite) TestLoadTimeLayouts() {
+	key := Variable[time.Time]("ENV_TIME_LAYOUT")
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("RFC3339", func() {
+		s.setEnvVar(string(key), "2024-05-01T12:30:00Z")
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback, TimeLayouts(time.RFC1123)))
+		assert.Equal(s.T(), time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), cfg.Time(key).UTC())
+	})
+	s.Run("Layout", func() {
+		s.setEnvVar(string(key), "Wed, 01 May 2024 12:30:00 UTC")
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback, TimeLayouts(time.Kitchen, time.RFC1123)))
+		assert.Equal(s.T(), time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), cfg.Time(key).UTC())
+	})
+	s.Run("Invalid", func() {
+		s.setEnvVar(string(key), "01/05/2024")
+		cfg := NewConfigImpl()
+		err := LoadEnvironment(cfg, key, fallback, TimeLayouts(time.RFC1123))
+		s.ErrorContains(err, `not an RFC 3339 timestamp, local date, local time or time in layouts ["Mon, 02 Jan 2006 15:04:05 MST"]`)
+		assert.Equal(s.T(), fallback, cfg.Time(key))
+	})
+}
+
+func (s *LoadEnvironmentSuite) TestLoadDuration() {
+	key := Variable[time.Duration]("ENV_DURATION")
+	fallback := time.Minute
+
+	s.Run("EnvVarSetValid", func() {
+		s.setEnvVar(string(key), "1h5m30s")
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback))
+		assert.Equal(s.T(), time.Hour+5*time.Minute+30*time.Second, cfg.Duration(key))
+	})
+	s.Run("EnvVarNotSet", func() {
+		s.unsetEnvVar(string(key))
+		cfg := NewConfigImpl()
+		s.NoError(LoadEnvironment(cfg, key, fallback))
+		assert.Equal(s.T(), fallback, cfg.Duration(key))
+	})
+	s.Run("BareNumberWithoutUnit", func() {
+		s.setEnvVar(string(key), "30")
+		cfg := NewConfigImpl()
+		err := LoadEnvironment(cfg, key, fallback)
+		var parseErr *ParseError
+		s.Require().ErrorAs(err, &parseErr)
+		s.Equal("time.Duration", parseErr.Type)
+		s.ErrorContains(err, "missing unit in duration")
+		assert.Equal(s.T(), fallback, cfg.Duration(key))
+	})
+	s.Run("BareNumberWithUnit", func() {
+		cfg := NewConfigImpl()
+		s.setEnvVar(string(key), "30")
+		s.NoError(LoadEnvironment(cfg, key, fallback, DurationUnit(time.Second)))
+		assert.Equal(s.T(), 30*time.Second, cfg.Duration(key))
+
+		s.setEnvVar(string(key), "1.5")
+		s.NoError(LoadEnvironment(cfg, key, fallback, DurationUnit(time.Second)))
+		assert.Equal(s.T(), 1500*time.Millisecond, cfg.Duration(key))
+
+		s.setEnvVar(string(key), "250ms")
+		s.NoError(LoadEnvironment(cfg, key, fallback, DurationUnit(time.Second)))
+		assert.Equal(s.T(), 250*time.Millisecond, cfg.Duration(key))
+	})
+	s.Run("BareNumberOutOfRange", func() {
+		cfg := NewConfigImpl()
+		for _, raw := range []string{"10000000000", "1e10", "NaN"} {
+			s.setEnvVar(string(key), raw)
+			err := LoadEnvironment(cfg, key, fallback, DurationUnit(time.Second))
+			s.ErrorContains(err, "duration of "+raw+" times 1s out of range")
+			assert.Equal(s.T(), fallback, cfg.Duration(key))
+		}
+	})
+	s.Run("Document", func() {
+		src, err := ParseJSON("config.json", strings.NewReader(`{"timeout": 15, "interval": "2m", "retry": true}`))
+		s.Require().NoError(err)
+		cfg := NewConfigImpl()
+		cfg.SetSources(src)
+		s.NoError(LoadEnvironment(cfg, Variable[time.Duration]("TIMEOUT"), fallback, DurationUnit(time.Second)))
+		s.NoError(LoadEnvironment(cfg, Variable[time.Duration]("INTERVAL"), fallback))
+		s.ErrorIs(LoadEnvironment(cfg, Variable[time.Duration]("RETRY"), fallback), ErrTypeMismatch)
+		assert.Equal(s.T(), 15*time.Second, cfg.Duration("TIMEOUT"))
+		assert.Equal(s.T(), 2*time.Minute, cfg.Duration("INTERVAL"))
+	})
+	s.Run("Reload", func() {
+		s.setEnvVar(string(key), "10")
+		cfg := NewConfigImpl()
+		s.Require().NoError(LoadEnvironment(cfg, key, fallback, DurationUnit(time.Millisecond)))
+		s.setEnvVar(string(key), "20")
+		s.Require().NoError(cfg.Reload())
+		assert.Equal(s.T(), 20*time.Millisecond, cfg.Duration(key))
+	})
+}
+
+func (s *LoadEnvironmentSuite) TestLoadIntegerLiterals() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"MASK":    "0xFF",
+		"MODE":    "0o755",
+		"LEGACY":  "0755",
+		"FLAGS":   "0b1010",
+		"LIMIT":   "1_000_000",
+		"OFFSET":  "-0x80",
+		"POINTER": "0xdead_beef",
+		"DECIMAL": "42",
+	}))
+
+	s.Run("Decimal", func() {
+		err := LoadEnvironment(cfg, Variable[int]("MASK"), 1)
+		s.ErrorIs(err, strconv.ErrSyntax)
+		s.ErrorIs(LoadEnvironment(cfg, Variable[int]("LIMIT"), 1), strconv.ErrSyntax)
+		s.Require().NoError(LoadEnvironment(cfg, Variable[int]("LEGACY"), 1))
+		assert.Equal(s.T(), 755, cfg.Int("LEGACY"), "a leading zero is decimal by default")
+	})
+	s.Run("EveryWidth", func() {
+		s.Require().NoError(errors.Join(
+			LoadEnvironment(cfg, Variable[uint8]("MASK"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[uint16]("MODE"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[int32]("LEGACY"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[int16]("FLAGS"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[int64]("LIMIT"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[int8]("OFFSET"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[uintptr]("POINTER"), 0, IntegerLiterals()),
+			LoadEnvironment(cfg, Variable[uint]("DECIMAL"), 0, IntegerLiterals()),
+		))
+		assert.Equal(s.T(), uint8(255), cfg.Uint8("MASK"))
+		assert.Equal(s.T(), uint16(0o755), cfg.Uint16("MODE"))
+		assert.Equal(s.T(), int32(0o755), cfg.Int32("LEGACY"))
+		assert.Equal(s.T(), int16(10), cfg.Int16("FLAGS"))
+		assert.Equal(s.T(), int64(1_000_000), cfg.Int64("LIMIT"))
+		assert.Equal(s.T(), int8(-128), cfg.Int8("OFFSET"))
+		assert.Equal(s.T(), uintptr(0xdeadbeef), cfg.Uintptr("POINTER"))
+		assert.Equal(s.T(), uint(42), cfg.Uint("DECIMAL"))
+	})
+	s.Run("Invalid", func() {
+		for _, raw := range []string{"0x", "0o8", "0b2", "1__000", "_1", "0x_"} {
+			cfg := NewConfigImpl()
+			cfg.SetSources(MapSource("test", map[string]string{"VALUE": raw}))
+			err := LoadEnvironment(cfg, Variable[int]("VALUE"), 7, IntegerLiterals())
+			s.ErrorIs(err, strconv.ErrSyntax, raw)
+			assert.Equal(s.T(), 7, cfg.Int("VALUE"))
+		}
+	})
+	s.Run("Flag", func() {
+		cfg := NewConfigImpl()
+		s.Require().NoError(LoadEnvironment(cfg, Variable[uint32]("MODE"), 0o644, IntegerLiterals()))
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		s.Require().NoError(BindFlags(cfg, fs))
+		s.Require().NoError(fs.Parse([]string{"--mode=0o600"}))
+		assert.Equal(s.T(), uint32(0o600), cfg.Uint32("MODE"))
+	})
+}
+
+func (s *LoadEnvironmentSuite) TestLoadIntegerBounds() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"SMALL": "128",
+		"TINY":  "-129",
+		"BYTE":  "0x100",
+		"BIG":   "9223372036854775808",
+		"HUGE":  "18446744073709551616",
+	}))
+
+	tests := []struct {
+		load func() error
+		want string
+	}{
+		{func() error { return LoadEnvironment(cfg, Variable[int8]("SMALL"), 0) }, "between -128 and 127"},
+		{func() error { return LoadEnvironment(cfg, Variable[int8]("TINY"), 0) }, "between -128 and 127"},
+		{func() error { return LoadEnvironment(cfg, Variable[uint8]("BYTE"), 0, IntegerLiterals()) }, "between 0 and 255"},
+		{func() error { return LoadEnvironment(cfg, Variable[int64]("BIG"), 0) }, "between -9223372036854775808 and 9223372036854775807"},
+		{func() error { return LoadEnvironment(cfg, Variable[uint64]("HUGE"), 0) }, "between 0 and 18446744073709551615"},
+	}
+	for _, tt := range tests {
+		err := tt.load()
+		s.ErrorIs(err, strconv.ErrRange)
+		s.ErrorContains(err, "value out of range, must be "+tt.want)
+	}
+
+	err := LoadEnvironment(cfg, Variable[int8]("SMALL"), 0)
+	s.EqualError(err, `invalid value "128" for configuration variable SMALL of type int8 from test: value out of range, must be between -128 and 127`)
+	var numErr *strconv.NumError
+	s.Require().ErrorAs(err, &numErr)
+	s.Equal("128", numErr.Num)
+}
+
 func (s *LoadEnvironmentSuite) TestLoadBool() {
 	key := Variable[bool]("ENV_BOOL")
 
@@ -765,6 +1047,65 @@ func (s *LoadEnvironmentSuite) TestLoadRunes() {
 	})
 }
 
+func (s *LoadEnvironmentSuite) TestLoadReturnsParseError() {
+	key := Variable[int]("ENV_STRICT_PORT")
+	s.setEnvVar(string(key), "80a")
+
+	cfg := NewConfigImpl()
+	err := LoadEnvironment(cfg, key, 3000)
+	s.Require().Error(err)
+
+	var parseErr *ParseError
+	s.Require().True(errors.As(err, &parseErr))
+	assert.Equal(s.T(), string(key), parseErr.Key)
+	assert.Equal(s.T(), "80a", parseErr.Value)
+	assert.Equal(s.T(), "int", parseErr.Type)
+	assert.ErrorIs(s.T(), err, strconv.ErrSyntax)
+
+	// The fallback is still registered, so non-strict callers keep the previous behaviour.
+	assert.Equal(s.T(), 3000, cfg.Int(key))
+}
+
+func (s *LoadEnvironmentSuite) TestLoadAggregatesParseErrors() {
+	portKey := Variable[int]("ENV_AGG_PORT")
+	sizeKey := Variable[uint8]("ENV_AGG_SIZE")
+	nameKey := Variable[string]("ENV_AGG_NAME")
+	s.setEnvVar(string(portKey), "80a")
+	s.setEnvVar(string(sizeKey), "300")
+	s.setEnvVar(string(nameKey), "name")
+
+	cfg := NewConfigImpl()
+	err := LoadAll(cfg,
+		Load(portKey, 3000),
+		Load(sizeKey, 8),
+		Load(nameKey, "fallback"),
+	)
+	s.Require().Error(err)
+
+	var loadErr *LoadError
+	s.Require().ErrorAs(err, &loadErr)
+	s.Len(loadErr.Errs, 2)
+	parseErrs := loadErr.ParseErrors()
+	s.Require().Len(parseErrs, 2)
+	assert.Equal(s.T(), string(portKey), parseErrs[0].Key)
+	assert.Equal(s.T(), string(sizeKey), parseErrs[1].Key)
+	s.EqualError(err, "2 configuration variables failed to load:\n\t"+parseErrs[0].Error()+"\n\t"+parseErrs[1].Error())
+
+	var parseErr *ParseError
+	s.Require().True(errors.As(err, &parseErr))
+	assert.Equal(s.T(), string(portKey), parseErr.Key)
+	assert.ErrorIs(s.T(), err, strconv.ErrSyntax)
+	assert.ErrorIs(s.T(), err, strconv.ErrRange)
+
+	assert.Equal(s.T(), 3000, cfg.Int(portKey), "every load runs, even after a failure")
+	assert.Equal(s.T(), uint8(8), cfg.Uint8(sizeKey))
+	assert.Equal(s.T(), "name", cfg.String(nameKey))
+
+	s.setEnvVar(string(portKey), "8080")
+	s.Require().NoError(LoadAll(cfg, Load(portKey, 3000), Load(nameKey, "fallback")), "nil when every load succeeds")
+	s.EqualError(LoadAll(cfg, Load(sizeKey, 8)), loadErr.Errs[1].Error(), "a single failure is reported as is")
+}
+
 // --- Test Methods for FormatKeysSuite ---
 
 func (s *FormatKeysSuite) TestFormatKeys() {
@@ -797,10 +1138,10 @@ func (s *CheckKeySuite) TestCheckKey() {
 	missingIntKey := Variable[int]("MISSING_INT")
 	uintptrKey := Variable[uintptr]("MY_UINTPTR_UNINIT_MAP_SCENARIO")
 
-	cfg.regString[strKey] = "value"
-	cfg.regInt[intKey] = 123
-	cfg.regBool[boolKey] = true
-	cfg.regFloat32[float32Key] = 3.14
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[string]]string{strKey: "value"}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[int]]int{intKey: 123}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[bool]]bool{boolKey: true}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[float32]]float32{float32Key: 3.14}))
 
 	s.Run("ExistingKeys", func() {
 		name, exists := cfg.checkKey(strKey)
@@ -857,8 +1198,8 @@ func (s *ConfigurationKeysRegisteredSuite) TestConfigurationKeysRegistered() {
 	intKey1 := Variable[int]("INT_KEY_1")
 	floatKeyMissing := Variable[float32]("FLOAT_KEY_MISSING")
 
-	cfg.regString[strKey1] = "val1"
-	cfg.regInt[intKey1] = 100
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[string]]string{strKey1: "val1"}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[int]]int{intKey1: 100}))
 
 	s.Run("AllCheckedKeysExist", func() {
 		err := cfg.ConfigurationKeysRegistered(strKey1, intKey1)
@@ -869,12 +1210,16 @@ func (s *ConfigurationKeysRegisteredSuite) TestConfigurationKeysRegistered() {
 		err := cfg.ConfigurationKeysRegistered(strKey1, strKey2Missing, intKey1, floatKeyMissing)
 		s.Require().Error(err)
 
-		var missingErr missingVariableError
-		s.Require().True(errors.As(err, &missingErr), "Error should be of type missingVariableError")
+		var missingErr MissingVariableError
+		s.Require().True(errors.As(err, &missingErr), "Error should be of type MissingVariableError")
 
 		s.Require().ErrorIs(err, ErrMissingVariable, "Error should unwrap to ErrMissingVariable")
 
 		assert.ElementsMatch(s.T(), []string{string(strKey2Missing), string(floatKeyMissing)}, missingErr.Keys)
+		assert.Equal(s.T(), []MissingVariable{
+			{Key: string(strKey2Missing), Type: "string"},
+			{Key: string(floatKeyMissing), Type: "float32"},
+		}, missingErr.Variables)
 		assert.Contains(s.T(), err.Error(), "missing configuration variables:")
 		assert.Contains(s.T(), err.Error(), string(strKey2Missing))
 		assert.Contains(s.T(), err.Error(), string(floatKeyMissing))
@@ -885,12 +1230,27 @@ func (s *ConfigurationKeysRegisteredSuite) TestConfigurationKeysRegistered() {
 		missingInt := Variable[int]("COMPLETELY_MISSING_I")
 		err := cfg.ConfigurationKeysRegistered(missingStr, missingInt)
 		s.Require().Error(err)
-		var missingErr missingVariableError
+		var missingErr MissingVariableError
 		s.Require().True(errors.As(err, &missingErr))
 		assert.ElementsMatch(s.T(), []string{string(missingStr), string(missingInt)}, missingErr.Keys)
 		s.Require().ErrorIs(err, ErrMissingVariable)
 	})
 
+	s.Run("ExpectedTypes", func() {
+		err := cfg.ConfigurationKeysRegistered(
+			Variable[[]byte]("MISSING_BYTES"),
+			Variable[uint16]("MISSING_PORT"),
+			"NOT_A_VARIABLE",
+		)
+		var missingErr MissingVariableError
+		s.Require().ErrorAs(err, &missingErr)
+		assert.Equal(s.T(), []MissingVariable{
+			{Key: "MISSING_BYTES", Type: "[]uint8"},
+			{Key: "MISSING_PORT", Type: "uint16"},
+			{Key: "", Type: "string"},
+		}, missingErr.Variables)
+	})
+
 	s.Run("NoKeysToCheck", func() {
 		err := cfg.ConfigurationKeysRegistered()
 		assert.NoError(s.T(), err)
@@ -900,8 +1260,8 @@ func (s *ConfigurationKeysRegisteredSuite) TestConfigurationKeysRegistered() {
 		err := cfg.ConfigurationKeysRegistered(Variable[string]("ANY_MISSING_KEY"))
 		s.Require().Error(err)
 
-		_, ok := err.(missingVariableError)
-		assert.True(s.T(), ok, "Error should be missingVariableError type")
+		_, ok := err.(MissingVariableError)
+		assert.True(s.T(), ok, "Error should be MissingVariableError type")
 
 		assert.ErrorIs(s.T(), err, ErrMissingVariable, "Error should unwrap to ErrMissingVariable via errors.Is")
 
@@ -920,6 +1280,8 @@ func TestConfiguraSuite(t *testing.T) {
 	suite.Run(t, new(ConfigurationKeysRegisteredSuite))
 	suite.Run(t, new(FallbackSuite))
 	suite.Run(t, new(MergeSuite))
+	suite.Run(t, new(NamedTypeSuite))
+	suite.Run(t, new(TextUnmarshalerSuite))
 }
 
 // TestMergeEmpty tests merging an empty list of configs.
@@ -930,23 +1292,25 @@ func (s *MergeSuite) TestMergeEmpty() {
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok, "Merged config should be of type *ConfigImpl")
 
-	s.Empty(cfgImpl.regString, "RegString should be empty")
-	s.Empty(cfgImpl.regInt, "RegInt should be empty")
-	s.Empty(cfgImpl.regInt8, "RegInt8 should be empty")
-	s.Empty(cfgImpl.regInt16, "RegInt16 should be empty")
-	s.Empty(cfgImpl.regInt32, "RegInt32 should be empty")
-	s.Empty(cfgImpl.regInt64, "RegInt64 should be empty")
-	s.Empty(cfgImpl.regUint, "RegUint should be empty")
-	s.Empty(cfgImpl.regUint8, "RegUint8 should be empty")
-	s.Empty(cfgImpl.regUint16, "RegUint16 should be empty")
-	s.Empty(cfgImpl.regUint32, "RegUint32 should be empty")
-	s.Empty(cfgImpl.regUint64, "RegUint64 should be empty")
-	s.Empty(cfgImpl.regUintptr, "RegUintptr should be empty")
-	s.Empty(cfgImpl.regBytes, "RegBytes should be empty")
-	s.Empty(cfgImpl.regRunes, "RegRunes should be empty")
-	s.Empty(cfgImpl.regFloat32, "RegFloat32 should be empty")
-	s.Empty(cfgImpl.regFloat64, "RegFloat64 should be empty")
-	s.Empty(cfgImpl.regBool, "RegBool should be empty")
+	s.Empty(cfgImpl.load().regString, "RegString should be empty")
+	s.Empty(cfgImpl.load().regInt, "RegInt should be empty")
+	s.Empty(cfgImpl.load().regInt8, "RegInt8 should be empty")
+	s.Empty(cfgImpl.load().regInt16, "RegInt16 should be empty")
+	s.Empty(cfgImpl.load().regInt32, "RegInt32 should be empty")
+	s.Empty(cfgImpl.load().regInt64, "RegInt64 should be empty")
+	s.Empty(cfgImpl.load().regUint, "RegUint should be empty")
+	s.Empty(cfgImpl.load().regUint8, "RegUint8 should be empty")
+	s.Empty(cfgImpl.load().regUint16, "RegUint16 should be empty")
+	s.Empty(cfgImpl.load().regUint32, "RegUint32 should be empty")
+	s.Empty(cfgImpl.load().regUint64, "RegUint64 should be empty")
+	s.Empty(cfgImpl.load().regUintptr, "RegUintptr should be empty")
+	s.Empty(cfgImpl.load().regBytes, "RegBytes should be empty")
+	s.Empty(cfgImpl.load().regRunes, "RegRunes should be empty")
+	s.Empty(cfgImpl.load().regFloat32, "RegFloat32 should be empty")
+	s.Empty(cfgImpl.load().regFloat64, "RegFloat64 should be empty")
+	s.Empty(cfgImpl.load().regBool, "RegBool should be empty")
+	s.Empty(cfgImpl.load().regTime, "RegTime should be empty")
+	s.Empty(cfgImpl.load().regDuration, "RegDuration should be empty")
 }
 
 // TestMergeSingle tests merging a single configuration.
@@ -967,10 +1331,10 @@ func (s *MergeSuite) TestMergeSingle() {
 	// Ensure it has copied values correctly
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok, "Merged config should be of type *ConfigImpl")
-	s.Equal("value1", cfgImpl.regString[keyStr])
-	s.Equal(123, cfgImpl.regInt[keyInt])
-	s.Len(cfgImpl.regString, 1)
-	s.Len(cfgImpl.regInt, 1)
+	s.Equal("value1", cfgImpl.load().regString[keyStr])
+	s.Equal(123, cfgImpl.load().regInt[keyInt])
+	s.Len(cfgImpl.load().regString, 1)
+	s.Len(cfgImpl.load().regInt, 1)
 }
 
 // TestMergeTwoDistinct tests merging two configurations with distinct keys.
@@ -991,10 +1355,10 @@ func (s *MergeSuite) TestMergeTwoDistinct() {
 
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok, "Merged config should be of type *ConfigImpl")
-	s.Len(cfgImpl.regString, 1, "RegString should have 1 entry")
-	s.Equal("value1", cfgImpl.regString[keyStr1])
-	s.Len(cfgImpl.regInt, 1, "RegInt should have 1 entry")
-	s.Equal(100, cfgImpl.regInt[keyInt1])
+	s.Len(cfgImpl.load().regString, 1, "RegString should have 1 entry")
+	s.Equal("value1", cfgImpl.load().regString[keyStr1])
+	s.Len(cfgImpl.load().regInt, 1, "RegInt should have 1 entry")
+	s.Equal(100, cfgImpl.load().regInt[keyInt1])
 }
 
 // TestMergeTwoOverride tests merging two configurations where the second overrides the first.
@@ -1019,12 +1383,12 @@ func (s *MergeSuite) TestMergeTwoOverride() {
 
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok, "Merged config should be of type *ConfigImpl")
-	s.Len(cfgImpl.regString, 1)
-	s.Equal("overridden_value", cfgImpl.regString[keyStr])
-	s.Len(cfgImpl.regInt, 1)
-	s.Equal(111, cfgImpl.regInt[keyInt])
-	s.Len(cfgImpl.regBool, 1)
-	s.True(cfgImpl.regBool[keyBool])
+	s.Len(cfgImpl.load().regString, 1)
+	s.Equal("overridden_value", cfgImpl.load().regString[keyStr])
+	s.Len(cfgImpl.load().regInt, 1)
+	s.Equal(111, cfgImpl.load().regInt[keyInt])
+	s.Len(cfgImpl.load().regBool, 1)
+	s.True(cfgImpl.load().regBool[keyBool])
 }
 
 // TestMergeMultiple tests merging multiple (three) configurations with overrides.
@@ -1055,9 +1419,9 @@ func (s *MergeSuite) TestMergeMultiple() {
 
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok)
-	s.Len(cfgImpl.regString, 2) // S1, SHARED_KEY
-	s.Len(cfgImpl.regInt, 1)    // I1
-	s.Len(cfgImpl.regBool, 1)   // B1
+	s.Len(cfgImpl.load().regString, 2) // S1, SHARED_KEY
+	s.Len(cfgImpl.load().regInt, 1)    // I1
+	s.Len(cfgImpl.load().regBool, 1)   // B1
 }
 
 // TestMergeAllTypes ensures all supported types are merged correctly.
@@ -1159,23 +1523,23 @@ func (s *MergeSuite) TestMergeAllTypes() {
 
 	cfgImpl, ok := mergedCfg.(*ConfigImpl)
 	s.Require().True(ok)
-	s.Len(cfgImpl.regString, 1)
-	s.Len(cfgImpl.regInt, 1)
-	s.Len(cfgImpl.regInt8, 1)
-	s.Len(cfgImpl.regInt16, 1)
-	s.Len(cfgImpl.regInt32, 1)
-	s.Len(cfgImpl.regInt64, 1)
-	s.Len(cfgImpl.regUint, 1)
-	s.Len(cfgImpl.regUint8, 1)
-	s.Len(cfgImpl.regUint16, 1)
-	s.Len(cfgImpl.regUint32, 1)
-	s.Len(cfgImpl.regUint64, 1)
-	s.Len(cfgImpl.regUintptr, 1)
-	s.Len(cfgImpl.regBytes, 1)
-	s.Len(cfgImpl.regRunes, 1)
-	s.Len(cfgImpl.regFloat32, 1)
-	s.Len(cfgImpl.regFloat64, 1)
-	s.Len(cfgImpl.regBool, 1)
+	s.Len(cfgImpl.load().regString, 1)
+	s.Len(cfgImpl.load().regInt, 1)
+	s.Len(cfgImpl.load().regInt8, 1)
+	s.Len(cfgImpl.load().regInt16, 1)
+	s.Len(cfgImpl.load().regInt32, 1)
+	s.Len(cfgImpl.load().regInt64, 1)
+	s.Len(cfgImpl.load().regUint, 1)
+	s.Len(cfgImpl.load().regUint8, 1)
+	s.Len(cfgImpl.load().regUint16, 1)
+	s.Len(cfgImpl.load().regUint32, 1)
+	s.Len(cfgImpl.load().regUint64, 1)
+	s.Len(cfgImpl.load().regUintptr, 1)
+	s.Len(cfgImpl.load().regBytes, 1)
+	s.Len(cfgImpl.load().regRunes, 1)
+	s.Len(cfgImpl.load().regFloat32, 1)
+	s.Len(cfgImpl.load().regFloat64, 1)
+	s.Len(cfgImpl.load().regBool, 1)
 }
 
 func (s *FallbackSuite) TestFallbackString() {
@@ -1270,3 +1634,287 @@ func (s *FallbackSuite) TestFallbackBool() {
 		})
 	}
 }
+
+// Types defined on top of the basic types, used by NamedTypeSuite.
+type (
+	Port     uint16
+	LogLevel string
+	Ratio    float64
+	Enabled  bool
+	Token    []byte
+	Verbose  int
+)
+
+// String gives Verbose a textual form that differs from the one it is parsed from.
+func (v Verbose) String() string {
+	return fmt.Sprintf("verbosity %d", int(v))
+}
+
+func (s *NamedTypeSuite) TestLoadEnvironment() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"PORT":      "8080",
+		"LOG_LEVEL": "debug",
+		"RATIO":     "0.5",
+		"ENABLED":   "true",
+		"TOKEN":     "abc",
+		"VERBOSE":   "2",
+	}))
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[Port]("PORT"), 80),
+		LoadEnvironment(cfg, Variable[LogLevel]("LOG_LEVEL"), "info"),
+		LoadEnvironment(cfg, Variable[Ratio]("RATIO"), 1),
+		LoadEnvironment(cfg, Variable[Enabled]("ENABLED"), false),
+		LoadEnvironment(cfg, Variable[Token]("TOKEN"), nil),
+		LoadEnvironment(cfg, Variable[Verbose]("VERBOSE"), 0),
+		LoadEnvironment(cfg, Variable[Port]("MISSING_PORT"), 443),
+	))
+
+	s.Equal(Port(8080), Get(cfg, Variable[Port]("PORT")))
+	s.Equal(LogLevel("debug"), Get(cfg, Variable[LogLevel]("LOG_LEVEL")))
+	s.Equal(Ratio(0.5), Get(cfg, Variable[Ratio]("RATIO")))
+	s.Equal(Enabled(true), Get(cfg, Variable[Enabled]("ENABLED")))
+	s.Equal(Token("abc"), Get(cfg, Variable[Token]("TOKEN")))
+	s.Equal(Verbose(2), Get(cfg, Variable[Verbose]("VERBOSE")))
+	s.Equal(Port(443), Get(cfg, Variable[Port]("MISSING_PORT")))
+	s.Equal("test", cfg.Origin(Variable[Port]("PORT")))
+
+	_, ok := Lookup(cfg, Variable[uint16]("PORT"))
+	s.False(ok, "a named type is kept apart from its underlying type")
+	s.Equal(uint16(0), cfg.Uint16("PORT"))
+}
+
+func (s *NamedTypeSuite) TestParseError() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{"PORT": "70000"}))
+
+	err := LoadEnvironment(cfg, Variable[Port]("PORT"), 80)
+	s.EqualError(err, `invalid value "70000" for configuration variable PORT of type configura.Port from test: value out of range, must be between 0 and 65535`)
+	s.ErrorIs(err, strconv.ErrRange)
+	s.Equal(Port(80), Get(cfg, Variable[Port]("PORT")))
+}
+
+func (s *NamedTypeSuite) TestWriteConfiguration() {
+	cfg := NewConfigImpl()
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[Port]]Port{"PORT": 8080, "ADMIN_PORT": 9090}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[LogLevel]]LogLevel{"LOG_LEVEL": "warn"}))
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[Port]]Port{"PORT": 8081}))
+
+	s.Equal(Port(8081), Get(cfg, Variable[Port]("PORT")))
+	_, ok := Lookup(cfg, Variable[Port]("ADMIN_PORT"))
+	s.False(ok, "writing replaces the variables of the same type")
+	s.Equal(LogLevel("warn"), Get(cfg, Variable[LogLevel]("LOG_LEVEL")), "variables of other types are kept")
+}
+
+func (s *NamedTypeSuite) TestConfigurationKeysRegistered() {
+	cfg := NewConfigImpl()
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[Port]]Port{"PORT": 8080}))
+
+	s.NoError(cfg.ConfigurationKeysRegistered(Variable[Port]("PORT")))
+
+	err := cfg.ConfigurationKeysRegistered(Variable[Port]("PORT"), Variable[LogLevel]("LOG_LEVEL"), Variable[uint16]("PORT"))
+	var missing MissingVariableError
+	s.Require().ErrorAs(err, &missing)
+	s.Equal([]MissingVariable{
+		{Key: "LOG_LEVEL", Type: "configura.LogLevel"},
+		{Key: "PORT", Type: "uint16"},
+	}, missing.Variables)
+}
+
+func (s *NamedTypeSuite) TestMerge() {
+	cfg1 := NewConfigImpl()
+	s.Require().NoError(WriteConfiguration(cfg1, map[Variable[Port]]Port{"PORT": 8080, "ADMIN_PORT": 9090}))
+	cfg2 := NewConfigImpl()
+	s.Require().NoError(WriteConfiguration(cfg2, map[Variable[Port]]Port{"PORT": 8081}))
+	s.Require().NoError(WriteConfiguration(cfg2, map[Variable[LogLevel]]LogLevel{"LOG_LEVEL": "warn"}))
+
+	merged := Merge(cfg1, cfg2)
+	s.Equal(Port(8081), Get(merged, Variable[Port]("PORT")))
+	s.Equal(Port(9090), Get(merged, Variable[Port]("ADMIN_PORT")))
+	s.Equal(LogLevel("warn"), Get(merged, Variable[LogLevel]("LOG_LEVEL")))
+	s.NoError(merged.ConfigurationKeysRegistered(Variable[Port]("ADMIN_PORT"), Variable[LogLevel]("LOG_LEVEL")))
+}
+
+func (s *NamedTypeSuite) TestSubscribeAndFlags() {
+	cfg := NewConfigImpl()
+	cfg.SetSources()
+	s.Require().NoError(LoadEnvironment(cfg, Variable[Token]("TOKEN"), Token("abc")))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[Verbose]("VERBOSE"), 1))
+
+	var changes []Change[Token]
+	defer Subscribe(cfg, Variable[Token]("TOKEN"), func(change Change[Token]) { changes = append(changes, change) })()
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal("1", fs.Lookup("verbose").DefValue, "defaults are shown in the form they are parsed from")
+	s.Require().NoError(fs.Parse([]string{"--token=abc", "--verbose=3"}))
+	s.Empty(changes, "setting the same value is not a change")
+	s.Require().NoError(fs.Parse([]string{"--token=def"}))
+
+	s.Equal([]Change[Token]{{Key: "TOKEN", Old: Token("abc"), New: Token("def")}}, changes)
+	s.Equal(Verbose(3), Get(cfg, Variable[Verbose]("VERBOSE")))
+}
+
+// Level is an enum parsed from its name with encoding.TextUnmarshaler, used by TextUnmarshalerSuite.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+var levelNames = []string{"debug", "info"}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	i := slices.Index(levelNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown level %q", text)
+	}
+	*l = Level(i)
+	return nil
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(levelNames[l]), nil
+}
+
+// URL wraps url.URL, which doesn't implement encoding.TextUnmarshaler itself.
+type URL struct {
+	*url.URL
+}
+
+func (u *URL) UnmarshalText(text []byte) error {
+	parsed, err := url.Parse(string(text))
+	u.URL = parsed
+	return err
+}
+
+func (s *TextUnmarshalerSuite) TestLoadEnvironment() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"LEVEL":    "info",
+		"ADDR":     "10.0.0.1",
+		"NETWORK":  "10.0.0.0/8",
+		"IP":       "192.168.1.1",
+		"PATTERN":  "^v[0-9]+$",
+		"ENDPOINT": "https://example.com/api",
+	}))
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[Level]("LEVEL"), LevelDebug),
+		LoadEnvironment(cfg, Variable[netip.Addr]("ADDR"), netip.Addr{}),
+		LoadEnvironment(cfg, Variable[netip.Prefix]("NETWORK"), netip.Prefix{}),
+		LoadEnvironment(cfg, Variable[net.IP]("IP"), nil),
+		LoadEnvironment(cfg, Variable[*regexp.Regexp]("PATTERN"), nil),
+		LoadEnvironment(cfg, Variable[URL]("ENDPOINT"), URL{}),
+		LoadEnvironment(cfg, Variable[netip.Addr]("MISSING"), netip.MustParseAddr("127.0.0.1")),
+	))
+
+	s.Equal(LevelInfo, Get(cfg, Variable[Level]("LEVEL")))
+	s.Equal(netip.MustParseAddr("10.0.0.1"), Get(cfg, Variable[netip.Addr]("ADDR")))
+	s.True(Get(cfg, Variable[netip.Prefix]("NETWORK")).Contains(netip.MustParseAddr("10.1.2.3")))
+	s.Equal(net.ParseIP("192.168.1.1"), Get(cfg, Variable[net.IP]("IP")))
+	s.True(Get(cfg, Variable[*regexp.Regexp]("PATTERN")).MatchString("v12"))
+	s.Equal("example.com", Get(cfg, Variable[URL]("ENDPOINT")).Host)
+	s.Equal(netip.MustParseAddr("127.0.0.1"), Get(cfg, Variable[netip.Addr]("MISSING")))
+
+	s.NoError(cfg.ConfigurationKeysRegistered(Variable[Level]("LEVEL"), Variable[*regexp.Regexp]("PATTERN")))
+	merged := Merge(NewConfigImpl(), cfg)
+	s.Equal(netip.MustParseAddr("10.0.0.1"), Get(merged, Variable[netip.Addr]("ADDR")))
+}
+
+func (s *TextUnmarshalerSuite) TestErrors() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{"LEVEL": "loud", "ADDR": "10.0.0", "CHANNEL": "x"}))
+
+	err := LoadEnvironment(cfg, Variable[Level]("LEVEL"), LevelDebug)
+	s.EqualError(err, `invalid value "loud" for configuration variable LEVEL of type configura.Level from test: unknown level "loud"`)
+	s.Equal(LevelDebug, Get(cfg, Variable[Level]("LEVEL")))
+
+	var parseErr *ParseError
+	s.ErrorAs(LoadEnvironment(cfg, Variable[netip.Addr]("ADDR"), netip.Addr{}), &parseErr)
+	s.Equal("netip.Addr", parseErr.Type)
+
+	err = LoadEnvironment(cfg, Variable[chan int]("CHANNEL"), nil)
+	s.ErrorIs(err, ErrUnsupportedType)
+}
+
+func (s *TextUnmarshalerSuite) TestUnsupportedTypeUnset() {
+	cfg := NewConfigImpl()
+	cfg.SetSources()
+
+	err := LoadEnvironment(cfg, Variable[chan int]("CHANNEL"), nil)
+	s.EqualError(err, "configuration variable CHANNEL of type chan int: unsupported type")
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal(ParseError{Key: "CHANNEL", Type: "chan int", Err: ErrUnsupportedType}, *parseErr)
+	s.ErrorIs(cfg.ConfigurationKeysRegistered(Variable[chan int]("CHANNEL")), ErrMissingVariable, "the variable isn't registered")
+
+	s.ErrorIs(LoadEnvironment(cfg, Variable[[]func()]("HOOKS"), nil), ErrUnsupportedType)
+	s.ErrorIs(LoadEnvironment(cfg, Variable[map[string]chan int]("QUEUES"), nil), ErrUnsupportedType)
+
+	err = WriteConfiguration(cfg, map[Variable[chan int]]chan int{"CHANNEL": make(chan int)})
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("CHANNEL", parseErr.Key)
+	s.ErrorIs(WriteConfiguration(cfg, map[Variable[chan int]]chan int{}), ErrUnsupportedType)
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.ErrorIs(BindFlag(cfg, fs, Variable[chan int]("CHANNEL"), ""), ErrUnsupportedType)
+	s.Nil(fs.Lookup("channel"))
+}
+
+func (s *TextUnmarshalerSuite) TestDocument() {
+	src, err := ParseJSON("config.json", strings.NewReader(`{"level": "debug", "addr": "::1", "network": {"ip": "10.0.0.1"}}`))
+	s.Require().NoError(err)
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[Level]("LEVEL"), LevelInfo))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[netip.Addr]("ADDR"), netip.Addr{}))
+	s.Equal(LevelDebug, Get(cfg, Variable[Level]("LEVEL")))
+	s.Equal(netip.IPv6Loopback(), Get(cfg, Variable[netip.Addr]("ADDR")))
+
+	s.ErrorIs(LoadEnvironment(cfg, Variable[netip.Prefix]("NETWORK"), netip.Prefix{}), ErrTypeMismatch)
+}
+
+func (s *TextUnmarshalerSuite) TestMarshalText() {
+	cfg := NewConfigImpl()
+	cfg.SetSources()
+	s.Require().NoError(LoadEnvironment(cfg, Variable[Level]("LEVEL"), LevelInfo))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[netip.Addr]("ADDR"), netip.MustParseAddr("127.0.0.1")))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[*regexp.Regexp]("PATTERN"), nil))
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal("info", fs.Lookup("level").DefValue)
+	s.Equal("127.0.0.1", fs.Lookup("addr").DefValue)
+	s.Equal("", fs.Lookup("pattern").DefValue)
+
+	s.Require().NoError(fs.Parse([]string{"--level=debug", "--pattern=^a"}))
+	s.Equal(LevelDebug, Get(cfg, Variable[Level]("LEVEL")))
+	s.Equal("^a", Get(cfg, Variable[*regexp.Regexp]("PATTERN")).String())
+}
+
+func (s *TextUnmarshalerSuite) TestSubscribe() {
+	patterns := map[string]string{"PATTERN": "^a"}
+	cfg := NewConfigImpl()
+	cfg.SetSources(SourceFunc("test", func(key string) (string, bool) {
+		v, ok := patterns[key]
+		return v, ok
+	}))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[*regexp.Regexp]("PATTERN"), nil))
+
+	var changes []Change[*regexp.Regexp]
+	defer Subscribe(cfg, Variable[*regexp.Regexp]("PATTERN"), func(change Change[*regexp.Regexp]) {
+		changes = append(changes, change)
+	})()
+
+	s.Require().NoError(cfg.Reload())
+	s.Empty(changes, "patterns are compared by value, not by pointer")
+	patterns["PATTERN"] = "^b"
+	s.Require().NoError(cfg.Reload())
+	s.Require().Len(changes, 1)
+	s.Equal("^a", changes[0].Old.String())
+	s.Equal("^b", changes[0].New.String())
+}
@@ -2,8 +2,10 @@ package configura
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -169,6 +171,84 @@ func (s *ThreadSafetySuite) TestConcurrentAccess() {
 	}
 }
 
+func (s *ThreadSafetySuite) TestIndependentInstancesDoNotBlock() {
+	locked := NewConfigImpl()
+	other := NewConfigImpl()
+	key := Variable[string]("KEY")
+	s.Require().NoError(WriteConfiguration(other, map[Variable[string]]string{key: "value"}))
+
+	// Hold the write lock of one instance, every operation on the other instance must still complete.
+	locked.mu.Lock()
+	defer locked.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = other.String(key)
+		_ = WriteConfiguration(other, map[Variable[int]]int{"INT_KEY": 1})
+		LoadEnvironment(other, Variable[bool]("BOOL_KEY"), true)
+		_ = other.ConfigurationKeysRegistered(key)
+		_ = Merge(other, NewConfigImpl())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("operations on an independent instance blocked on another instance's lock")
+	}
+}
+
+func (s *ThreadSafetySuite) TestReadsDoNotBlockOnWriters() {
+	cfg := NewConfigImpl()
+	key := Variable[string]("KEY")
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[string]]string{key: "value"}))
+
+	// Hold the writer lock of the instance, reads must still be served from the current snapshot.
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	done := make(chan string)
+	go func() {
+		done <- cfg.String(key)
+	}()
+
+	select {
+	case value := <-done:
+		s.Equal("value", value)
+	case <-time.After(time.Second):
+		s.Fail("read blocked on a writer")
+	}
+}
+
+func (s *ThreadSafetySuite) TestSnapshotConsistency() {
+	cfg := NewConfigImpl()
+	strKey := Variable[string]("COUNTER")
+	intKey := Variable[int]("COUNTER")
+	s.Require().NoError(cfg.update(func(r *registry) error {
+		r.regString = with(r.regString, strKey, "0")
+		r.regInt = with(r.regInt, intKey, 0)
+		return nil
+	}))
+
+	const writes = 1000
+	go func() {
+		for i := 1; i <= writes; i++ {
+			_ = cfg.update(func(r *registry) error {
+				r.regString = with(r.regString, strKey, strconv.Itoa(i))
+				r.regInt = with(r.regInt, intKey, i)
+				return nil
+			})
+		}
+	}()
+
+	s.runConcurrently(10, func(int) {
+		for i := 0; i < writes; i++ {
+			snapshot := cfg.Snapshot()
+			s.Equal(strconv.Itoa(snapshot.Int(intKey)), snapshot.String(strKey))
+		}
+	})
+}
+
 func TestThreadSafety(t *testing.T) {
 	suite.Run(t, new(ThreadSafetySuite))
 }
@@ -0,0 +1,83 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+)
+
+// CredentialsDirectoryEnv is the environment variable systemd sets to the directory holding the credentials passed
+// to a service with LoadCredential=, SetCredential= and similar settings.
+const CredentialsDirectoryEnv = "CREDENTIALS_DIRECTORY"
+
+// ErrNoCredentialsDirectory is returned by CredentialsSource and LoadCredentials when CREDENTIALS_DIRECTORY is not
+// set, e.g. because the service isn't started by systemd or is given no credentials.
+var ErrNoCredentialsDirectory = errors.New(CredentialsDirectoryEnv + " is not set")
+
+// CredentialsSource reads the systemd credentials of the service from the directory named by $CREDENTIALS_DIRECTORY,
+// and returns a Source serving them. The source is named after the directory. Credentials are served under their
+// name, matched the same way as the keys of JSON documents, so a credential named db-password serves DB_PASSWORD.
+// Their content is served exactly as stored, and is treated as a secret: it isn't shown as the default of command-line
+// flags.
+//
+// The mapping, which may be nil, names the credential of a configuration variable explicitly, e.g.
+// {"DATABASE_PASSWORD": "db-password"}. The required configuration variables must be served by a credential,
+// otherwise an error naming every missing credential is returned, which wraps a MissingVariableError. Each of them
+// must be a Variable, an error is returned for any other value, such as a plain string.
+func CredentialsSource(mapping map[string]string, required ...any) (Source, error) {
+	dir, ok := os.LookupEnv(CredentialsDirectoryEnv)
+	if !ok || dir == "" {
+		return nil, ErrNoCredentialsDirectory
+	}
+
+	mapping = maps.Clone(mapping)
+	values, err := readDirectory(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	for key, name := range mapping {
+		if v, ok := values[normalizeKey(name)]; ok {
+			values[normalizeKey(key)] = v
+		}
+	}
+
+	var missing MissingVariableError
+	var names []string
+	for _, key := range required {
+		v, ok := key.(variable)
+		if !ok {
+			return nil, fmt.Errorf("required credential %#v is not a configuration variable", key)
+		}
+		keyName := v.name()
+		if _, ok := values[normalizeKey(keyName)]; ok {
+			continue
+		}
+		name := keyName
+		if mapped, ok := mapping[keyName]; ok {
+			name = mapped
+		}
+		names = append(names, fmt.Sprintf("%s (for %s)", name, keyName))
+		missing.Keys = append(missing.Keys, keyName)
+		missing.Variables = append(missing.Variables, MissingVariable{Key: keyName, Type: v.typeName()})
+	}
+	if len(missing.Keys) > 0 {
+		return nil, fmt.Errorf("systemd credentials %s not found in %s: %w", strings.Join(names, ", "), dir, missing)
+	}
+
+	src := documentSource{name: dir, values: values, secrets: true}
+	read := func() (Source, error) { return CredentialsSource(mapping, required...) }
+	return fileSource{Source: src, path: dir, read: read}, nil
+}
+
+// LoadCredentials reads the systemd credentials of the service and adds them as the source with the lowest
+// precedence to the configuration, see CredentialsSource and LoadDotenv.
+func LoadCredentials(config *ConfigImpl, mapping map[string]string, required ...any) error {
+	src, err := CredentialsSource(mapping, required...)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
@@ -0,0 +1,108 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// CredentialsSuite tests loading configuration from systemd credentials. A temporary directory stands in for the
+// credentials directory of a service.
+type CredentialsSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *CredentialsSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.T().Setenv(CredentialsDirectoryEnv, s.dir)
+}
+
+func (s *CredentialsSuite) write(name, content string) {
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o400))
+}
+
+func (s *CredentialsSuite) TestLoadCredentials() {
+	s.write("db-password", "s3cret\n")
+	s.write("api_token", "token")
+	s.write("PORT", "8443")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadCredentials(cfg, map[string]string{"DATABASE_PASSWORD": "db-password"}))
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[string]("DATABASE_PASSWORD"), ""),
+		LoadEnvironment(cfg, Variable[[]byte]("API_TOKEN"), nil),
+		LoadEnvironment(cfg, Variable[uint16]("PORT"), 0),
+	))
+
+	s.Equal("s3cret\n", cfg.String("DATABASE_PASSWORD"), "credentials are served exactly as stored")
+	s.Equal([]byte("token"), cfg.Bytes("API_TOKEN"))
+	s.Equal(uint16(8443), cfg.Uint16("PORT"))
+	s.Equal(s.dir, cfg.Origin(Variable[uint16]("PORT")))
+}
+
+func (s *CredentialsSuite) TestParseErrorHidesValue() {
+	s.write("pin", "12a4")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadCredentials(cfg, nil))
+	err := LoadEnvironment(cfg, Variable[int]("PIN"), 0)
+	s.ErrorIs(err, strconv.ErrSyntax)
+	s.NotContains(err.Error(), "12a4")
+}
+
+func (s *CredentialsSuite) TestEnvironmentTakesPrecedence() {
+	s.write("PORT", "8443")
+	s.T().Setenv("PORT", "9000")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadCredentials(cfg, nil))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("PORT"), 0))
+	s.Equal(9000, cfg.Int("PORT"))
+}
+
+func (s *CredentialsSuite) TestRequired() {
+	s.write("db-password", "s3cret")
+
+	_, err := CredentialsSource(map[string]string{"DATABASE_PASSWORD": "db-password"}, Variable[string]("DATABASE_PASSWORD"))
+	s.NoError(err)
+
+	_, err = CredentialsSource(
+		map[string]string{"TLS_KEY": "tls-key"},
+		Variable[string]("DATABASE_PASSWORD"),
+		Variable[[]byte]("TLS_KEY"),
+		Variable[string]("API_TOKEN"),
+	)
+	s.EqualError(err, "systemd credentials DATABASE_PASSWORD (for DATABASE_PASSWORD), tls-key (for TLS_KEY), API_TOKEN (for API_TOKEN) not found in "+s.dir+": missing configuration variables: DATABASE_PASSWORD, TLS_KEY, API_TOKEN")
+	s.ErrorIs(err, ErrMissingVariable)
+
+	var missing MissingVariableError
+	s.Require().ErrorAs(err, &missing)
+	s.Equal(MissingVariable{Key: "TLS_KEY", Type: "[]uint8"}, missing.Variables[1])
+
+	_, err = CredentialsSource(nil, "DATABASE_PASSWORD")
+	s.EqualError(err, `required credential "DATABASE_PASSWORD" is not a configuration variable`)
+}
+
+func (s *CredentialsSuite) TestNoCredentialsDirectory() {
+	s.T().Setenv(CredentialsDirectoryEnv, "")
+
+	cfg := NewConfigImpl()
+	s.ErrorIs(LoadCredentials(cfg, nil), ErrNoCredentialsDirectory)
+	s.Len(cfg.Sources(), 1)
+}
+
+func (s *CredentialsSuite) TestMissingDirectory() {
+	s.T().Setenv(CredentialsDirectoryEnv, filepath.Join(s.dir, "missing"))
+
+	_, err := CredentialsSource(nil)
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestCredentialsSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsSuite))
+}
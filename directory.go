@@ -0,0 +1,95 @@
+package configura
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// kubernetesDataDir is the symlink Kubernetes points at the current version of a mounted ConfigMap or Secret. The
+// files of the mount are symlinks through it, and updates swap it atomically to a new, timestamped directory.
+const kubernetesDataDir = "..data"
+
+// DirectorySource reads every file of the directory at path, and returns a Source serving their contents keyed by
+// file name. The source is named after the path. File names are matched the same way as the keys of JSON documents,
+// see ParseJSON, so a file named database.url serves DATABASE_URL. A single trailing newline is removed from every
+// file if trimNewline is set.
+//
+// This is the layout of ConfigMaps and Secrets mounted as volumes by Kubernetes. When the directory holds a ..data
+// symlink, the files are read from the directory it points at, so all values come from the same version of the
+// mount even while Kubernetes swaps it. Hidden entries, whose names start with a dot, and subdirectories are
+// skipped.
+//
+// The values are treated as secrets, as Secrets and ConfigMaps are mounted the same way: they are not shown as the
+// default of command-line flags.
+func DirectorySource(path string, trimNewline bool) (Source, error) {
+	values, err := readDirectory(path, trimNewline)
+	if err != nil {
+		return nil, err
+	}
+	src := documentSource{name: path, values: values, secrets: true}
+	return fileSource{Source: src, path: path, read: func() (Source, error) { return DirectorySource(path, trimNewline) }}, nil
+}
+
+// readDirectory reads the files of the directory at path into document values keyed by normalized file name, see
+// DirectorySource.
+func readDirectory(path string, trimNewline bool) (map[string]documentValue, error) {
+	dir := path
+	if target, err := filepath.EvalSymlinks(filepath.Join(path, kubernetesDataDir)); err == nil {
+		dir = target
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]documentValue, len(entries))
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		file := filepath.Join(dir, name)
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key := normalizeKey(name)
+		if other, ok := names[key]; ok {
+			return nil, fmt.Errorf("%s: files %s and %s map to the same configuration variable %s", path, other, name, key)
+		}
+		names[key] = name
+
+		raw := string(content)
+		if trimNewline {
+			raw = trimTrailingNewline(raw)
+		}
+		values[key] = documentValue{raw: raw, kind: kindString}
+	}
+	return values, nil
+}
+
+// LoadDirectory reads the files of the directory at path and adds them as the source with the lowest precedence to
+// the configuration, see DirectorySource and LoadDotenv.
+func LoadDirectory(config *ConfigImpl, path string, trimNewline bool) error {
+	src, err := DirectorySource(path, trimNewline)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
@@ -0,0 +1,127 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// DirectorySuite tests loading configuration from directories holding one file per variable.
+type DirectorySuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *DirectorySuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+func (s *DirectorySuite) write(path, content string) {
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o700))
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+}
+
+// mountVersion lays out files the way Kubernetes mounts a ConfigMap: the files are stored in a timestamped
+// directory, ..data points at it, and every key is a symlink through ..data. The ..data symlink is swapped
+// atomically if it exists.
+func (s *DirectorySuite) mountVersion(version string, files map[string]string) {
+	for name, content := range files {
+		s.write(filepath.Join(s.dir, version, name), content)
+		link := filepath.Join(s.dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			s.Require().NoError(os.Symlink(filepath.Join(kubernetesDataDir, name), link))
+		}
+	}
+
+	tmp := filepath.Join(s.dir, "..data_tmp")
+	s.Require().NoError(os.Symlink(version, tmp))
+	s.Require().NoError(os.Rename(tmp, filepath.Join(s.dir, kubernetesDataDir)))
+}
+
+func (s *DirectorySuite) TestPlainDirectory() {
+	s.write(filepath.Join(s.dir, "DATABASE_URL"), "postgres://localhost/db\n")
+	s.write(filepath.Join(s.dir, "port"), "8080")
+	s.write(filepath.Join(s.dir, ".hidden"), "ignored")
+	s.write(filepath.Join(s.dir, "nested", "KEY"), "ignored")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadDirectory(cfg, s.dir, true))
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[string]("DATABASE_URL"), ""),
+		LoadEnvironment(cfg, Variable[int]("PORT"), 0),
+		LoadEnvironment(cfg, Variable[string]("HIDDEN"), "fallback"),
+		LoadEnvironment(cfg, Variable[string]("NESTED_KEY"), "fallback"),
+	))
+
+	s.Equal("postgres://localhost/db", cfg.String("DATABASE_URL"))
+	s.Equal(8080, cfg.Int("PORT"))
+	s.Equal(s.dir, cfg.Origin(Variable[int]("PORT")))
+	s.Equal("fallback", cfg.String("HIDDEN"))
+	s.Equal("fallback", cfg.String("NESTED_KEY"))
+}
+
+func (s *DirectorySuite) TestParseErrorHidesValue() {
+	s.write(filepath.Join(s.dir, "PIN"), "12a4")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadDirectory(cfg, s.dir, true))
+	err := LoadEnvironment(cfg, Variable[int]("PIN"), 0)
+	s.EqualError(err, "invalid secret value for configuration variable PIN of type int from "+s.dir+": invalid syntax")
+	s.NotContains(err.Error(), "12a4")
+}
+
+func (s *DirectorySuite) TestKeepNewline() {
+	s.write(filepath.Join(s.dir, "CERT"), "-----BEGIN-----\n")
+
+	src, err := DirectorySource(s.dir, false)
+	s.Require().NoError(err)
+	value, ok := src.Lookup("CERT")
+	s.True(ok)
+	s.Equal("-----BEGIN-----\n", value)
+}
+
+func (s *DirectorySuite) TestKubernetesLayout() {
+	s.mountVersion("..2024_05_01_12_00_00.1", map[string]string{"log.level": "info\n", "PORT": "8080\n"})
+
+	src, err := DirectorySource(s.dir, true)
+	s.Require().NoError(err)
+	value, ok := src.Lookup("LOG_LEVEL")
+	s.True(ok)
+	s.Equal("info", value)
+	_, ok = src.Lookup("DATA")
+	s.False(ok, "..data must be skipped")
+
+	s.mountVersion("..2024_05_01_13_00_00.2", map[string]string{"log.level": "debug\n"})
+
+	src, err = DirectorySource(s.dir, true)
+	s.Require().NoError(err)
+	value, ok = src.Lookup("log.level")
+	s.True(ok)
+	s.Equal("debug", value)
+	_, ok = src.Lookup("PORT")
+	s.False(ok, "keys removed from the mount must not be served from the previous version")
+}
+
+func (s *DirectorySuite) TestDuplicateKeys() {
+	s.write(filepath.Join(s.dir, "database.url"), "a")
+	s.write(filepath.Join(s.dir, "DATABASE_URL"), "b")
+
+	_, err := DirectorySource(s.dir, true)
+	s.ErrorContains(err, "map to the same configuration variable DATABASE_URL")
+}
+
+func (s *DirectorySuite) TestErrors() {
+	_, err := DirectorySource(filepath.Join(s.dir, "missing"), true)
+	s.ErrorIs(err, os.ErrNotExist)
+
+	s.Require().NoError(os.Symlink(filepath.Join(s.dir, "missing"), filepath.Join(s.dir, "BROKEN")))
+	_, err = DirectorySource(s.dir, true)
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestDirectorySuite(t *testing.T) {
+	suite.Run(t, new(DirectorySuite))
+}
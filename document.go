@@ -0,0 +1,222 @@
+package configura
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of values held by structured documents, like JSON files.
+const (
+	kindString   = "string"
+	kindNumber   = "number"
+	kindFloat    = "float"
+	kindBool     = "bool"
+	kindDatetime = "datetime"
+	kindObject   = "object"
+	kindArray    = "array"
+)
+
+// documentValue is a value of a structured document, flattened to a single key.
+type documentValue struct {
+	raw    string // Textual representation of the value, as it is parsed into configuration variables.
+	kind   string // Kind of the value in the document, one of the kind constants.
+	line   int    // 1-based line of the value in the document, 0 if unknown.
+	column int    // 1-based column of the value in the document, 0 if unknown.
+	path   string // Path of the value in the document, joined with dots, e.g. database.url.
+	// fields maps the names of the members of an object, as written in the document, to their flattened key.
+	fields map[string]string
+	// elements holds the flattened keys of the elements of an array, in order.
+	elements []string
+}
+
+// documentSource is a Source serving the values of a structured document. Nested values are flattened to keys by
+// joining their path with a separator, so {"database": {"url": "..."}} is served as DATABASE_URL. Keys are matched
+// case-insensitively, and dots and dashes are treated as underscores, so the same value is also served as
+// database.url.
+type documentSource struct {
+	name   string
+	values map[string]documentValue
+	// secrets is set if the values are secrets, e.g. because they are systemd credentials.
+	secrets bool
+}
+
+func (s documentSource) Name() string {
+	return s.name
+}
+
+func (s documentSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[normalizeKey(key)]
+	return v.raw, ok
+}
+
+func (s documentSource) secret(string) bool {
+	return s.secrets
+}
+
+func (s documentSource) keys() []string {
+	return slices.Collect(maps.Keys(s.values))
+}
+
+// lookupDocument returns the value of the key, including its kind in the document.
+func (s documentSource) lookupDocument(key string) (documentValue, bool) {
+	v, ok := s.values[normalizeKey(key)]
+	return v, ok
+}
+
+// documentLookuper is implemented by sources serving structured documents, allowing values of a kind that can't be
+// stored in a configuration variable to be rejected rather than coerced.
+type documentLookuper interface {
+	lookupDocument(key string) (documentValue, bool)
+}
+
+// normalizeKey returns the key as it is stored in a documentSource.
+func normalizeKey(key string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(key))
+}
+
+// checkKind reports an error if a document value of the given kind can't be stored in a value of type t. Scalars are
+// coerced into textual types, numeric types accept numbers and booleans accept booleans; both also accept strings,
+// which are parsed as they would be from the environment. Floats, as typed by TOML and YAML documents, are only
+// accepted by floating-point types and durations, so 1.0 isn't silently loaded into an integer. Types implementing
+// encoding.TextUnmarshaler accept all scalars, and so do lists and maps, which are parsed as delimited lists. Arrays
+// are loaded into lists by lookupIndexed, and objects into maps by lookupEntries.
+func checkKind(t reflect.Type, kind string) error {
+	scalar := kind == kindString || kind == kindNumber || kind == kindFloat || kind == kindBool || kind == kindDatetime
+	var ok bool
+	switch {
+	case t == timeType:
+		ok = kind == kindDatetime || kind == kindString
+	case t == durationType:
+		ok = kind == kindNumber || kind == kindFloat || kind == kindString
+	case isTextUnmarshaler(t), isList(t), isMap(t):
+		ok = scalar
+	default:
+		switch t.Kind() {
+		case reflect.String, reflect.Slice:
+			ok = scalar
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			ok = kind == kindNumber || kind == kindString
+		case reflect.Float32, reflect.Float64:
+			ok = kind == kindNumber || kind == kindFloat || kind == kindString
+		case reflect.Bool:
+			ok = kind == kindBool || kind == kindString
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s value", ErrTypeMismatch, kind)
+	}
+	return nil
+}
+
+// flatten adds value and, for objects and arrays, all values nested in it to out. The key of a nested value is its
+// path, joined with separator. Array elements are keyed by their index. Null values are left out. Paths flattened to
+// the same key are reported by a *SyntaxError for the document name, see addValue.
+func flatten(name string, out map[string]documentValue, path []string, separator string, value any) error {
+	key := normalizeKey(strings.Join(path, separator))
+	var dv documentValue
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		fields := make(map[string]string, len(v))
+		for _, field := range slices.Sorted(maps.Keys(v)) {
+			nestedPath := append(path[:len(path):len(path)], field)
+			if err := flatten(name, out, nestedPath, separator, v[field]); err != nil {
+				return err
+			}
+			if v[field] != nil {
+				fields[field] = normalizeKey(strings.Join(nestedPath, separator))
+			}
+		}
+		if len(path) == 0 {
+			return nil
+		}
+		dv = documentValue{raw: marshalRaw(v), kind: kindObject, fields: fields}
+	case []any:
+		elements := make([]string, len(v))
+		for i := range v {
+			elements[i] = normalizeKey(strings.Join(append(path[:len(path):len(path)], strconv.Itoa(i)), separator))
+		}
+		dv = documentValue{raw: marshalRaw(v), kind: kindArray, elements: elements}
+		if err := addValue(name, out, key, path, dv); err != nil {
+			return err
+		}
+		for i, nested := range v {
+			if err := flatten(name, out, append(path[:len(path):len(path)], strconv.Itoa(i)), separator, nested); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return flatten(name, out, path, separator, items)
+	case string:
+		dv = documentValue{raw: v, kind: kindString}
+	case bool:
+		dv = documentValue{raw: strconv.FormatBool(v), kind: kindBool}
+	case json.Number:
+		dv = documentValue{raw: v.String(), kind: kindNumber}
+	case float64:
+		dv = documentValue{raw: strconv.FormatFloat(v, 'g', -1, 64), kind: kindFloat}
+	case int64:
+		dv = documentValue{raw: strconv.FormatInt(v, 10), kind: kindNumber}
+	case time.Time:
+		dv = documentValue{raw: formatDatetime(v), kind: kindDatetime}
+	default:
+		return fmt.Errorf("unsupported value %v of type %T at %s", v, v, strings.Join(path, separator))
+	}
+	return addValue(name, out, key, path, dv)
+}
+
+// addValue adds the value at path to out under key. If another path was flattened to the same key, e.g. database.url
+// and database_url, a *SyntaxError naming both paths is returned, rather than one of them silently shadowing the
+// other.
+func addValue(name string, out map[string]documentValue, key string, path []string, value documentValue) error {
+	value.path = strings.Join(path, ".")
+	if other, ok := out[key]; ok {
+		paths := []string{other.path, value.path}
+		slices.Sort(paths)
+		return &SyntaxError{
+			Source: name,
+			Line:   value.line,
+			Column: value.column,
+			Msg:    fmt.Sprintf("%q and %q are both loaded as %s", paths[0], paths[1], key),
+		}
+	}
+	out[key] = value
+	return nil
+}
+
+// formatDatetime formats a datetime decoded from a document so it can be parsed back by parseTime. Local dates and
+// times, which TOML decoders mark by their location name, are formatted without a time zone.
+func formatDatetime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format(time.DateOnly)
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// marshalRaw returns the JSON representation of a value decoded from a document, used as the raw form of objects
+// and arrays in errors.
+func marshalRaw(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
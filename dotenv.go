@@ -0,0 +1,360 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DotenvFile reads and parses the dotenv file at path, and returns a Source serving its values. The source is named
+// after the path. See ParseDotenv for the supported syntax.
+func DotenvFile(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values, err := ParseDotenv(f)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if errors.As(err, &syntaxErr) {
+			syntaxErr.Source = path
+		}
+		return nil, err
+	}
+	src := mapSource{name: path, values: values}
+	return fileSource{Source: src, path: path, read: func() (Source, error) { return DotenvFile(path) }}, nil
+}
+
+// LoadDotenv reads the dotenv file at path and adds it as the source with the lowest precedence to the configuration,
+// so values set in the environment or in sources added earlier take precedence over the file. Variables loaded
+// with LoadEnvironment after this call can draw their values from the file.
+func LoadDotenv(config *ConfigImpl, path string) error {
+	src, err := DotenvFile(path)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
+
+// ParseDotenv parses the dotenv formatted content of r into a map of keys to values. The following syntax is
+// supported:
+//
+//	# Comments take a full line, or follow a value after whitespace.
+//	export KEY=value            # The export prefix is optional.
+//	UNQUOTED=some value         # Surrounding whitespace is trimmed.
+//	SINGLE='literal ${VALUE}'   # Single quoted values are taken literally, and may span lines.
+//	DOUBLE="line\nnext"         # Double quoted values support \n, \r, \t, \", \\ and \$ escapes, and may span lines.
+//	URL=http://${HOST}:$PORT    # References are expanded in unquoted and double quoted values.
+//	NAME=${USER:-nobody}        # A default is used if the referenced variable is unset or empty.
+//
+// References are resolved against the keys defined earlier in the content first, and the environment second. Syntax
+// errors are reported as a *SyntaxError with the line and column of the problem.
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotenvParser{src: string(content), line: 1, values: make(map[string]string)}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.values, nil
+}
+
+// dotenvParser is a scanner over the content of a dotenv file, keeping track of the current line and column for
+// error reporting.
+type dotenvParser struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+	values    map[string]string
+}
+
+func (p *dotenvParser) parse() error {
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			return nil
+		}
+
+		switch p.peek() {
+		case '\n', '\r':
+			p.next()
+			continue
+		case '#':
+			p.skipComment()
+			continue
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		p.skipBlanks()
+		if p.eof() || p.peek() != '=' {
+			return p.errorf("expected '=' after %s", key)
+		}
+		p.next()
+		p.skipBlanks()
+
+		value, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		p.values[key] = value
+	}
+}
+
+// parseKey reads a variable name, dropping an optional export prefix.
+func (p *dotenvParser) parseKey() (string, error) {
+	key := p.readName()
+	exported := key == "export" && !p.eof() && isBlank(p.peek())
+	if exported {
+		p.skipBlanks()
+		key = p.readName()
+	}
+	if key == "" {
+		if exported {
+			return "", p.errorf("expected variable name after export")
+		}
+		return "", p.errorf("expected variable name, found %q", p.peek())
+	}
+	return key, nil
+}
+
+// readName reads a run of characters that are valid in a variable name.
+func (p *dotenvParser) readName() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+// parseValue reads the value following the '=' of an assignment, up to and including the end of its line.
+func (p *dotenvParser) parseValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+
+	switch p.peek() {
+	case '\'':
+		return p.parseSingleQuoted()
+	case '"':
+		return p.parseDoubleQuoted()
+	default:
+		return p.parseUnquoted()
+	}
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	line, col := p.line, p.column()
+	p.next()
+	end := strings.IndexByte(p.src[p.pos:], '\'')
+	if end < 0 {
+		return "", &SyntaxError{Line: line, Column: col, Msg: "unterminated single quoted value"}
+	}
+	value := p.advance(end)
+	p.next()
+	return value, p.finishLine()
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	line, col := p.line, p.column()
+	p.next()
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", &SyntaxError{Line: line, Column: col, Msg: "unterminated double quoted value"}
+		}
+
+		c := p.next()
+		switch c {
+		case '"':
+			return b.String(), p.finishLine()
+		case '\\':
+			if p.eof() {
+				continue
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$', '\'':
+				b.WriteByte(e)
+			case '\n':
+				// An escaped newline continues the value on the next line.
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		case '$':
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquoted() (string, error) {
+	var b strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && isBlank(p.src[p.pos-1]) {
+			p.skipComment()
+			break
+		}
+		p.next()
+		if c == '$' {
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimRight(b.String(), " \t\r"), nil
+}
+
+// expand resolves the variable reference following a '$' and writes its value to b. Both $NAME and ${NAME} are
+// supported, the latter with an optional ":-default". A '$' that doesn't start a reference is kept as is.
+func (p *dotenvParser) expand(b *strings.Builder) error {
+	line, col := p.line, p.column()-1
+	if p.eof() {
+		b.WriteByte('$')
+		return nil
+	}
+
+	if p.peek() != '{' {
+		start := p.pos
+		for !p.eof() && p.peek() != '.' && isNameChar(p.peek(), p.pos == start) {
+			p.next()
+		}
+		name := p.src[start:p.pos]
+		if name == "" {
+			b.WriteByte('$')
+			return nil
+		}
+		b.WriteString(p.resolve(name))
+		return nil
+	}
+
+	end := strings.IndexAny(p.src[p.pos:], "}\n")
+	if end < 0 || p.src[p.pos+end] != '}' {
+		return &SyntaxError{Line: line, Column: col, Msg: "unterminated variable reference"}
+	}
+	reference := p.advance(end + 1)
+	reference = reference[1 : len(reference)-1]
+
+	name, def, hasDefault := strings.Cut(reference, ":-")
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return r > 0x7f || !isNameChar(byte(r), false) }) >= 0 {
+		return &SyntaxError{Line: line, Column: col, Msg: fmt.Sprintf("invalid variable reference ${%s}", reference)}
+	}
+	value := p.resolve(name)
+	if value == "" && hasDefault {
+		value = def
+	}
+	b.WriteString(value)
+	return nil
+}
+
+// resolve returns the value of a referenced variable, looking at keys defined earlier in the file first.
+func (p *dotenvParser) resolve(name string) string {
+	if value, ok := p.values[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// finishLine consumes the rest of the line after a quoted value, which may only hold whitespace and a comment.
+func (p *dotenvParser) finishLine() error {
+	p.skipBlanks()
+	if p.eof() {
+		return nil
+	}
+	switch p.peek() {
+	case '\r', '\n':
+		return nil
+	case '#':
+		p.skipComment()
+		return nil
+	default:
+		return p.errorf("unexpected character %q after quoted value", p.peek())
+	}
+}
+
+func (p *dotenvParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipBlanks() {
+	for !p.eof() && isBlank(p.peek()) {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+// next consumes and returns the current character, keeping track of line numbers.
+func (p *dotenvParser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+		p.lineStart = p.pos
+	}
+	return c
+}
+
+// advance consumes n characters and returns them.
+func (p *dotenvParser) advance(n int) string {
+	start := p.pos
+	for p.pos < start+n {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *dotenvParser) column() int {
+	return p.pos - p.lineStart + 1
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Column: p.column(), Msg: fmt.Sprintf(format, args...)}
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isNameChar reports whether c may be part of a variable name. Digits are not allowed as the first character.
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c == '.' || c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
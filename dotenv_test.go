@@ -0,0 +1,139 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// DotenvSuite tests parsing and loading of dotenv files.
+type DotenvSuite struct {
+	suite.Suite
+}
+
+func (s *DotenvSuite) parse(content string) map[string]string {
+	values, err := ParseDotenv(strings.NewReader(content))
+	s.Require().NoError(err)
+	return values
+}
+
+func (s *DotenvSuite) TestValues() {
+	s.T().Setenv("DOTENV_SUITE_USER", "alice")
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{"Unquoted", "KEY=value", map[string]string{"KEY": "value"}},
+		{"UnquotedTrimmed", "  KEY =  some value  \n", map[string]string{"KEY": "some value"}},
+		{"Empty", "KEY=\nOTHER=", map[string]string{"KEY": "", "OTHER": ""}},
+		{"Export", "export KEY=value\nexport\tOTHER=1", map[string]string{"KEY": "value", "OTHER": "1"}},
+		{"ExportAsKey", "export=value", map[string]string{"export": "value"}},
+		{"Comments", "# comment\nKEY=value # trailing\n  # indented\n", map[string]string{"KEY": "value"}},
+		{"HashInValue", "KEY=a#b", map[string]string{"KEY": "a#b"}},
+		{"SingleQuoted", `KEY='a "b" \n ${DOTENV_SUITE_USER} # c'`, map[string]string{"KEY": `a "b" \n ${DOTENV_SUITE_USER} # c`}},
+		{"SingleQuotedMultiline", "KEY='line 1\nline 2'\nNEXT=1", map[string]string{"KEY": "line 1\nline 2", "NEXT": "1"}},
+		{"DoubleQuoted", `KEY="a \"b\" # c"`, map[string]string{"KEY": `a "b" # c`}},
+		{"DoubleQuotedEscapes", `KEY="tab\there\nnew\\line \$HOME \q"`, map[string]string{"KEY": "tab\there\nnew\\line $HOME \\q"}},
+		{"DoubleQuotedMultiline", "KEY=\"-----BEGIN-----\nabc\n-----END-----\" # key\nNEXT=1", map[string]string{"KEY": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"}},
+		{"DoubleQuotedLineContinuation", "KEY=\"a\\\nb\"", map[string]string{"KEY": "ab"}},
+		{"CRLF", "KEY=value\r\nQUOTED=\"q\"\r\n", map[string]string{"KEY": "value", "QUOTED": "q"}},
+		{"ReferenceBraced", "HOST=localhost\nURL=http://${HOST}:8080", map[string]string{"HOST": "localhost", "URL": "http://localhost:8080"}},
+		{"ReferenceBare", "HOST=localhost\nURL=\"$HOST.local:$PORT_UNSET_DOTENV/\"", map[string]string{"HOST": "localhost", "URL": "localhost.local:/"}},
+		{"ReferenceEnvironment", "NAME=${DOTENV_SUITE_USER}", map[string]string{"NAME": "alice"}},
+		{"ReferenceFilePrecedence", "DOTENV_SUITE_USER=bob\nNAME=$DOTENV_SUITE_USER", map[string]string{"DOTENV_SUITE_USER": "bob", "NAME": "bob"}},
+		{"ReferenceDefault", "NAME=${DOTENV_UNSET:-nobody}\nSET=${DOTENV_SUITE_USER:-nobody}", map[string]string{"NAME": "nobody", "SET": "alice"}},
+		{"DollarLiteral", "PRICE=$5 and $", map[string]string{"PRICE": "$5 and $"}},
+		{"Redefinition", "KEY=1\nKEY=2", map[string]string{"KEY": "2"}},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, s.parse(tc.content))
+		})
+	}
+}
+
+func (s *DotenvSuite) TestSyntaxErrors() {
+	testCases := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		msg     string
+	}{
+		{"MissingEquals", "KEY=1\nOTHER value", 2, 7, "expected '=' after OTHER"},
+		{"InvalidKey", "KEY=1\n\n  1KEY=2", 3, 3, `expected variable name, found '1'`},
+		{"ExportAtEOF", "A=1\nexport ", 2, 8, "expected variable name after export"},
+		{"ExportTabAtEOF", "A=1\nexport\t", 2, 8, "expected variable name after export"},
+		{"ExportWithoutName", "export \nA=1", 1, 8, "expected variable name after export"},
+		{"UnterminatedSingle", "A=1\nKEY='abc\ndef", 2, 5, "unterminated single quoted value"},
+		{"UnterminatedDouble", "KEY=\"abc", 1, 5, "unterminated double quoted value"},
+		{"TrailingGarbage", "KEY=\"abc\" def", 1, 11, `unexpected character 'd' after quoted value`},
+		{"UnterminatedReference", "KEY=${ABC\n", 1, 5, "unterminated variable reference"},
+		{"InvalidReference", "KEY=\"${A B}\"", 1, 6, "invalid variable reference ${A B}"},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := ParseDotenv(strings.NewReader(tc.content))
+			var syntaxErr *SyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.Equal(tc.line, syntaxErr.Line)
+			s.Equal(tc.column, syntaxErr.Column)
+			s.Equal(tc.msg, syntaxErr.Msg)
+		})
+	}
+}
+
+func (s *DotenvSuite) TestDotenvFile() {
+	path := filepath.Join(s.T().TempDir(), ".env")
+	s.Require().NoError(os.WriteFile(path, []byte("PORT=8080\n"), 0o600))
+
+	src, err := DotenvFile(path)
+	s.Require().NoError(err)
+	s.Equal(path, src.Name())
+	value, ok := src.Lookup("PORT")
+	s.True(ok)
+	s.Equal("8080", value)
+
+	s.Run("SyntaxError", func() {
+		s.Require().NoError(os.WriteFile(path, []byte("PORT=8080\nBROKEN\n"), 0o600))
+		_, err := DotenvFile(path)
+		var syntaxErr *SyntaxError
+		s.Require().ErrorAs(err, &syntaxErr)
+		s.Equal(path, syntaxErr.Source)
+		s.Equal(path+":2:7: expected '=' after BROKEN", err.Error())
+	})
+
+	s.Run("MissingFile", func() {
+		_, err := DotenvFile(filepath.Join(s.T().TempDir(), "missing.env"))
+		s.True(errors.Is(err, os.ErrNotExist))
+	})
+}
+
+func (s *DotenvSuite) TestLoadDotenv() {
+	path := filepath.Join(s.T().TempDir(), ".env")
+	s.Require().NoError(os.WriteFile(path, []byte("DOTENV_PORT=8080\nDOTENV_HOST=file-host\n"), 0o600))
+	s.T().Setenv("DOTENV_HOST", "env-host")
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadDotenv(cfg, path))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("DOTENV_PORT"), 3000))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[string]("DOTENV_HOST"), "localhost"))
+
+	s.Equal(8080, cfg.Int("DOTENV_PORT"))
+	s.Equal(path, cfg.Origin(Variable[int]("DOTENV_PORT")))
+	s.Equal("env-host", cfg.String("DOTENV_HOST"), "the environment takes precedence over the file")
+
+	s.Error(LoadDotenv(cfg, filepath.Join(s.T().TempDir(), "missing.env")))
+}
+
+func TestDotenvSuite(t *testing.T) {
+	suite.Run(t, new(DotenvSuite))
+}
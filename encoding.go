@@ -0,0 +1,89 @@
+package configura
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Encoding is the way a []byte variable is written, see BytesEncoding.
+type Encoding int
+
+// Encodings of []byte variables.
+const (
+	// EncodingRaw takes the bytes of the value as they are. It is the default.
+	EncodingRaw Encoding = iota
+	// EncodingBase64 is standard base64, as defined in RFC 4648, with or without padding.
+	EncodingBase64
+	// EncodingBase64URL is URL-safe base64, as defined in RFC 4648, with or without padding.
+	EncodingBase64URL
+	// EncodingHex is hexadecimal, in upper or lower case.
+	EncodingHex
+)
+
+// BytesEncoding makes a []byte variable decode its value with the encoding, so binary data, like keys, can be given as
+// text. A value that can't be decoded is reported as such, the variable doesn't fall back to its raw bytes. The value
+// is encoded the same way when it is formatted, e.g. as the default of a command-line flag. Since such values are
+// usually keys, a *ParseError reporting one that can't be decoded leaves it out, as with Sensitive.
+func BytesEncoding(encoding Encoding) LoadOption {
+	return func(o *loadOptions) {
+		o.encoding = encoding
+	}
+}
+
+// ExactLength makes a []byte variable reject values that aren't n bytes long once decoded, e.g. a key of the wrong
+// size. A length of 0 or less disables the check. Values of the wrong length are left out of the *ParseError reporting
+// them, as with BytesEncoding.
+func ExactLength(n int) LoadOption {
+	return func(o *loadOptions) {
+		o.length = n
+	}
+}
+
+// decodeBytes decodes raw with the encoding of the options, and checks the length of the result.
+func decodeBytes(raw string, opts loadOptions) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch opts.encoding {
+	case EncodingBase64:
+		b, err = base64Encoding(raw, base64.StdEncoding).DecodeString(raw)
+	case EncodingBase64URL:
+		b, err = base64Encoding(raw, base64.URLEncoding).DecodeString(raw)
+	case EncodingHex:
+		b, err = hex.DecodeString(raw)
+	default:
+		b = []byte(raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.length > 0 && len(b) != opts.length {
+		return nil, fmt.Errorf("value is %d bytes long, expected %d", len(b), opts.length)
+	}
+	return b, nil
+}
+
+// base64Encoding returns the padded encoding, or its unpadded counterpart if raw isn't padded.
+func base64Encoding(raw string, padded *base64.Encoding) *base64.Encoding {
+	if len(raw)%4 != 0 && !strings.HasSuffix(raw, "=") {
+		return padded.WithPadding(base64.NoPadding)
+	}
+	return padded
+}
+
+// encodeBytes encodes b with the encoding of the options.
+func encodeBytes(b []byte, opts loadOptions) string {
+	switch opts.encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	case EncodingBase64URL:
+		return base64.URLEncoding.EncodeToString(b)
+	case EncodingHex:
+		return hex.EncodeToString(b)
+	default:
+		return string(b)
+	}
+}
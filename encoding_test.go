@@ -0,0 +1,140 @@
+package configura
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// EncodingSuite tests decoding []byte variables written in base64 or hexadecimal.
+type EncodingSuite struct {
+	suite.Suite
+}
+
+func TestEncodingSuite(t *testing.T) {
+	suite.Run(t, new(EncodingSuite))
+}
+
+var encodingKey = []byte{0xde, 0xad, 0xbe, 0xef, 0xfb, 0xff}
+
+func (s *EncodingSuite) TestDecode() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"RAW":          "plain",
+		"STD":          base64.StdEncoding.EncodeToString(encodingKey),
+		"STD_UNPADDED": base64.RawStdEncoding.EncodeToString(encodingKey[:4]),
+		"URL":          base64.URLEncoding.EncodeToString(encodingKey),
+		"URL_UNPADDED": base64.RawURLEncoding.EncodeToString(encodingKey[:5]),
+		"HEX":          hex.EncodeToString(encodingKey),
+		"HEX_UPPER":    strings.ToUpper(hex.EncodeToString(encodingKey)),
+		"EMPTY":        "",
+	}))
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[[]byte]("RAW"), nil),
+		LoadEnvironment(cfg, Variable[[]byte]("STD"), nil, BytesEncoding(EncodingBase64)),
+		LoadEnvironment(cfg, Variable[[]byte]("STD_UNPADDED"), nil, BytesEncoding(EncodingBase64)),
+		LoadEnvironment(cfg, Variable[[]byte]("URL"), nil, BytesEncoding(EncodingBase64URL)),
+		LoadEnvironment(cfg, Variable[[]byte]("URL_UNPADDED"), nil, BytesEncoding(EncodingBase64URL)),
+		LoadEnvironment(cfg, Variable[[]byte]("HEX"), nil, BytesEncoding(EncodingHex)),
+		LoadEnvironment(cfg, Variable[[]byte]("HEX_UPPER"), nil, BytesEncoding(EncodingHex)),
+		LoadEnvironment(cfg, Variable[[]byte]("EMPTY"), []byte("fallback"), BytesEncoding(EncodingHex)),
+	))
+
+	s.Equal([]byte("plain"), cfg.Bytes("RAW"))
+	s.Equal(encodingKey, cfg.Bytes("STD"))
+	s.Equal(encodingKey[:4], cfg.Bytes("STD_UNPADDED"))
+	s.Equal(encodingKey, cfg.Bytes("URL"))
+	s.Equal(encodingKey[:5], cfg.Bytes("URL_UNPADDED"))
+	s.Equal(encodingKey, cfg.Bytes("HEX"))
+	s.Equal(encodingKey, cfg.Bytes("HEX_UPPER"))
+	s.Empty(cfg.Bytes("EMPTY"))
+}
+
+func (s *EncodingSuite) TestInvalid() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"STD": base64.URLEncoding.EncodeToString(encodingKey),
+		"URL": base64.StdEncoding.EncodeToString(encodingKey),
+		"HEX": "abc",
+	}))
+
+	err := LoadEnvironment(cfg, Variable[[]byte]("STD"), []byte("fallback"), BytesEncoding(EncodingBase64))
+	s.EqualError(err, `invalid secret value for configuration variable STD of type []uint8 from test: illegal base64 data at input byte 3`)
+	s.Equal([]byte("fallback"), cfg.Bytes("STD"), "the raw value isn't used instead")
+
+	err = LoadEnvironment(cfg, Variable[[]byte]("URL"), nil, BytesEncoding(EncodingBase64URL))
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("URL", parseErr.Key)
+	s.True(parseErr.Redacted)
+	s.Empty(parseErr.Value)
+	s.NotContains(err.Error(), base64.StdEncoding.EncodeToString(encodingKey), "encoded keys are left out")
+
+	err = LoadEnvironment(cfg, Variable[[]byte]("HEX"), nil, BytesEncoding(EncodingHex))
+	s.ErrorIs(err, hex.ErrLength)
+}
+
+func (s *EncodingSuite) TestExactLength() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{
+		"KEY":   hex.EncodeToString(encodingKey),
+		"SHORT": hex.EncodeToString(encodingKey[:4]),
+		"RAW":   "123456",
+	}))
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]byte]("KEY"), nil, BytesEncoding(EncodingHex), ExactLength(6)))
+	s.Equal(encodingKey, cfg.Bytes("KEY"))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]byte]("RAW"), nil, ExactLength(6)))
+
+	err := LoadEnvironment(cfg, Variable[[]byte]("SHORT"), nil, BytesEncoding(EncodingHex), ExactLength(6))
+	s.EqualError(err, `invalid secret value for configuration variable SHORT of type []uint8 from test: value is 4 bytes long, expected 6`)
+}
+
+func (s *EncodingSuite) TestDocument() {
+	src, err := ParseJSON("config.json", strings.NewReader(`{"secret": "3q2+7/v/", "keys": ["deadbeef", "fbff"]}`))
+	s.Require().NoError(err)
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[[]byte]("SECRET"), nil, BytesEncoding(EncodingBase64)),
+		LoadEnvironment(cfg, Variable[[][]byte]("KEYS"), nil, BytesEncoding(EncodingHex)),
+	))
+	s.Equal(encodingKey, cfg.Bytes("SECRET"))
+	s.Equal([][]byte{encodingKey[:4], encodingKey[4:]}, Get(cfg, Variable[[][]byte]("KEYS")))
+}
+
+func (s *EncodingSuite) TestFlag() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{"SECRET": "deadbeef"}))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]byte]("SECRET"), nil, BytesEncoding(EncodingHex), ExactLength(4)))
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder))
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal("deadbeef", fs.Lookup("secret").DefValue, "the default is encoded back")
+
+	s.Require().NoError(fs.Parse([]string{"--secret=0badcafe"}))
+	s.Equal([]byte{0x0b, 0xad, 0xca, 0xfe}, cfg.Bytes("SECRET"))
+	s.Error(fs.Parse([]string{"--secret=cafe"}))
+}
+
+func (s *EncodingSuite) TestReload() {
+	values := map[string]string{"SECRET": "3q2+7w=="}
+	cfg := NewConfigImpl()
+	cfg.SetSources(SourceFunc("test", func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]byte]("SECRET"), nil, BytesEncoding(EncodingBase64)))
+
+	values["SECRET"] = "7/v/"
+	s.Require().NoError(cfg.Reload())
+	s.Equal(encodingKey[3:], cfg.Bytes("SECRET"), "the encoding is kept on reload")
+}
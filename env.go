@@ -1,187 +1,420 @@
 package configura
 
 import (
-	"os"
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
 	"strconv"
+	"time"
 )
 
+// LookupEnv retrieves the environment variable named by the key and converts it to the type of the key. The boolean
+// reports whether the variable is set. If it is set but can't be converted, a *ParseError is returned. The options
+// customize the conversion as they do for LoadEnvironment.
+func LookupEnv[T constraint](key Variable[T], opts ...LoadOption) (T, bool, error) {
+	value, _, ok, err := lookupSource(Environment, key, newLoadOptions(opts))
+	return value, ok, err
+}
+
+// envOrFallback returns the environment variable named by the key converted to T, or the fallback value if it is
+// unset or can't be converted.
+func envOrFallback[T constraint](key Variable[T], fallback T) T {
+	if value, ok, err := LookupEnv(key); ok && err == nil {
+		return value
+	}
+	return fallback
+}
+
+// parse converts a raw configuration value into T. If the conversion fails, a *ParseError describing the key, the
+// raw value and the target type is returned.
+func parse[T constraint](key, raw string, opts loadOptions) (T, error) {
+	var value T
+	if err := parseValue(reflect.ValueOf(&value).Elem(), raw, opts); err != nil {
+		var zero T
+		return zero, &ParseError{Key: key, Value: raw, Type: reflect.TypeFor[T]().String(), Err: err}
+	}
+	return value, nil
+}
+
+// parseValue parses raw into v, which must be settable. Types implementing encoding.TextUnmarshaler are parsed with it,
+// lists and maps are split into their elements, see parseList and parseMap, and other types defined on top of a basic
+// type are parsed like their underlying type.
+func parseValue(v reflect.Value, raw string, opts loadOptions) error {
+	switch t := v.Type(); {
+	case t == timeType:
+		value, err := parseTime(raw, opts.timeLayouts)
+		v.Set(reflect.ValueOf(value))
+		return err
+	case t == durationType:
+		value, err := parseDuration(raw, opts.durationUnit)
+		v.SetInt(int64(value))
+		return err
+	case isTextUnmarshaler(t):
+		return unmarshalText(v, raw)
+	case isList(t):
+		return parseList(v, raw, opts)
+	case isMap(t):
+		return parseMap(v, raw, opts)
+	}
+	return parseKind(v, raw, opts)
+}
+
+// supported reports whether values of type t can be loaded, following parseValue: lists and maps are supported if
+// their elements are.
+func supported(t reflect.Type) bool {
+	switch {
+	case t == timeType, t == durationType, isTextUnmarshaler(t):
+		return true
+	case isList(t):
+		return supported(t.Elem())
+	case isMap(t):
+		return supported(t.Key()) && supported(t.Elem())
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Slice:
+		kind := t.Elem().Kind()
+		return kind == reflect.Uint8 || kind == reflect.Int32
+	}
+	return false
+}
+
+// checkSupported returns a *ParseError wrapping ErrUnsupportedType for the configuration variable key if values of
+// type T can't be loaded. Types are checked up front, so a variable of an unsupported type is reported even if it
+// isn't set.
+func checkSupported[T constraint](key string) error {
+	if supported(reflect.TypeFor[T]()) {
+		return nil
+	}
+	return &ParseError{Key: key, Type: reflect.TypeFor[T]().String(), Err: ErrUnsupportedType}
+}
+
+// withBounds adds the bounds of the integer type t to err, if it reports a value out of the range of t. The error still
+// wraps strconv.ErrRange.
+func withBounds(err error, t reflect.Type) error {
+	numErr, ok := err.(*strconv.NumError)
+	if !ok || numErr.Err != strconv.ErrRange {
+		return err
+	}
+	bits := t.Bits()
+	var lower, upper string
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lower, upper = strconv.FormatInt(-1<<(bits-1), 10), strconv.FormatInt(1<<(bits-1)-1, 10)
+	default:
+		lower, upper = "0", strconv.FormatUint(math.MaxUint64>>(64-bits), 10)
+	}
+	return &strconv.NumError{
+		Func: numErr.Func,
+		Num:  numErr.Num,
+		Err:  fmt.Errorf("%w, must be between %s and %s", strconv.ErrRange, lower, upper),
+	}
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// parseKind parses raw into v according to the kind of its type. Byte slices are decoded with the encoding of the
+// options, see BytesEncoding.
+func parseKind(v reflect.Value, raw string, opts loadOptions) error {
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(raw, opts.integerBase(), v.Type().Bits())
+		v.SetInt(n)
+		err = withBounds(err, v.Type())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(raw, opts.integerBase(), v.Type().Bits())
+		v.SetUint(n)
+		err = withBounds(err, v.Type())
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, v.Type().Bits())
+		v.SetFloat(f)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		v.SetBool(b)
+	case reflect.Slice:
+		switch v.Type().Elem().Kind() {
+		case reflect.Uint8:
+			var b []byte
+			b, err = decodeBytes(raw, opts)
+			v.SetBytes(b)
+		case reflect.Int32:
+			v.Set(reflect.ValueOf([]rune(raw)).Convert(v.Type()))
+		default:
+			err = ErrUnsupportedType
+		}
+	default:
+		err = ErrUnsupportedType
+	}
+	return err
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// isTextUnmarshaler reports whether values of type t can be parsed with encoding.TextUnmarshaler, either because *t
+// implements it, or because t is a pointer to a type implementing it.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType) || t.Kind() == reflect.Pointer && t.Implements(textUnmarshalerType)
+}
+
+// unmarshalText parses raw into v with encoding.TextUnmarshaler, see isTextUnmarshaler. Pointers are allocated.
+func unmarshalText(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	ptr := reflect.New(v.Type().Elem())
+	if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+		return err
+	}
+	v.Set(ptr)
+	return nil
+}
+
 // Bool takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Bool(key Variable[bool], fallback bool) bool {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vBool, err := strconv.ParseBool(vStr); err == nil {
-			return vBool
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // String takes an environment key, and a fallback value. Returns environment value if it isn't unset.
 func String(key Variable[string], fallback string) string {
-	if v, ok := os.LookupEnv(string(key)); ok {
-		return v
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Int takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Int(key Variable[int], fallback int) int {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vInt, err := strconv.Atoi(vStr); err == nil {
-			return vInt
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Int8 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Int8(key Variable[int8], fallback int8) int8 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vInt, err := strconv.ParseInt(vStr, 10, 8); err == nil {
-			return int8(vInt)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Int16 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Int16(key Variable[int16], fallback int16) int16 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vInt, err := strconv.ParseInt(vStr, 10, 16); err == nil {
-			return int16(vInt)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Int32 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Int32(key Variable[int32], fallback int32) int32 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vInt, err := strconv.ParseInt(vStr, 10, 32); err == nil {
-			return int32(vInt)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Int64 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Int64(key Variable[int64], fallback int64) int64 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vInt, err := strconv.ParseInt(vStr, 10, 64); err == nil {
-			return vInt
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uint takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uint(key Variable[uint], fallback uint) uint {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vUint, err := strconv.ParseUint(vStr, 10, 0); err == nil { // 0 for uint
-			return uint(vUint)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uint8 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uint8(key Variable[uint8], fallback uint8) uint8 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vUint, err := strconv.ParseUint(vStr, 10, 8); err == nil {
-			return uint8(vUint)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uint16 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uint16(key Variable[uint16], fallback uint16) uint16 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vUint, err := strconv.ParseUint(vStr, 10, 16); err == nil {
-			return uint16(vUint)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uint32 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uint32(key Variable[uint32], fallback uint32) uint32 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vUint, err := strconv.ParseUint(vStr, 10, 32); err == nil {
-			return uint32(vUint)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uint64 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uint64(key Variable[uint64], fallback uint64) uint64 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vUint, err := strconv.ParseUint(vStr, 10, 64); err == nil {
-			return vUint
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Uintptr takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Uintptr(key Variable[uintptr], fallback uintptr) uintptr {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		// strconv.ParseUint is appropriate, bitSize 0 means infer from system (32 or 64)
-		if vUint, err := strconv.ParseUint(vStr, 10, strconv.IntSize); err == nil {
-			return uintptr(vUint)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Bytes takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
-// value if it fails.
+// value if it fails. The bytes of the variable are taken as they are; use LookupEnv with BytesEncoding to decode them.
 func Bytes(key Variable[[]byte], fallback []byte) []byte {
-	if v, ok := os.LookupEnv(string(key)); ok {
-		return []byte(v)
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Runes takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Runes(key Variable[[]rune], fallback []rune) []rune {
-	if v, ok := os.LookupEnv(string(key)); ok {
-		return []rune(v)
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Float32 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Float32(key Variable[float32], fallback float32) float32 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vFloat, err := strconv.ParseFloat(vStr, 32); err == nil {
-			return float32(vFloat)
-		}
-	}
-	return fallback
+	return envOrFallback(key, fallback)
 }
 
 // Float64 takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
 // value if it fails.
 func Float64(key Variable[float64], fallback float64) float64 {
-	if vStr, ok := os.LookupEnv(string(key)); ok {
-		if vFloat, err := strconv.ParseFloat(vStr, 64); err == nil {
-			return vFloat
+	return envOrFallback(key, fallback)
+}
+
+// Time takes an environment key, and a fallback value. Returns environment variable with converted type, or fallback
+// value if it fails. See parseTime for the accepted formats.
+func Time(key Variable[time.Time], fallback time.Time) time.Time {
+	return envOrFallback(key, fallback)
+}
+
+// Duration takes an environment key, and a fallback value. Returns environment variable with converted type, or
+// fallback value if it fails. The value must carry its units, like 30s or 1h5m, see time.ParseDuration.
+func Duration(key Variable[time.Duration], fallback time.Duration) time.Duration {
+	return envOrFallback(key, fallback)
+}
+
+// timeLayouts are the layouts accepted for time.Time configuration variables, in the order they are tried. Values
+// without a time zone are interpreted in the local time zone.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+	"15:04:05.999999999",
+}
+
+// parseTime parses an RFC 3339 timestamp, or a local date and time, local date or local time as written in TOML
+// documents, or a time in one of the additional layouts, see TimeLayouts.
+func parseTime(raw string, layouts []string) (time.Time, error) {
+	var firstErr error
+	for _, layout := range slices.Concat(timeLayouts, layouts) {
+		t, err := time.ParseInLocation(layout, raw, time.Local)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
 		}
 	}
-	return fallback
+	if len(layouts) > 0 {
+		return time.Time{}, fmt.Errorf("not an RFC 3339 timestamp, local date, local time or time in layouts %q: %w", layouts, firstErr)
+	}
+	return time.Time{}, fmt.Errorf("not an RFC 3339 timestamp, local date or local time: %w", firstErr)
+}
+
+// parseDuration parses a duration as accepted by time.ParseDuration, or, if unit isn't zero, a bare number of units,
+// see DurationUnit.
+func parseDuration(raw string, unit time.Duration) (time.Duration, error) {
+	if unit == 0 {
+		return time.ParseDuration(raw)
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+			return 0, fmt.Errorf("duration of %s times %s out of range", raw, unit)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		d := f * float64(unit)
+		if !(d >= math.MinInt64 && d < math.MaxInt64) {
+			return 0, fmt.Errorf("duration of %s times %s out of range", raw, unit)
+		}
+		return time.Duration(d), nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// format converts a configuration value into its raw form, which parse converts back into the same value.
+func format[T constraint](value T, opts loadOptions) string {
+	return formatValue(reflect.ValueOf(&value).Elem(), opts)
+}
+
+// formatValue converts v into its raw form, see format. Types implementing encoding.TextMarshaler are formatted with
+// it, lists and maps are joined with their separator, see formatList and formatMap.
+func formatValue(v reflect.Value, opts loadOptions) string {
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case durationType:
+		return time.Duration(v.Int()).String()
+	}
+	if m, ok := textMarshaler(v); ok {
+		text, err := m.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeBytes(v.Bytes(), opts)
+		}
+		if v.Type().Elem().Kind() == reflect.Int32 {
+			return string(v.Convert(reflect.TypeFor[[]rune]()).Interface().([]rune))
+		}
+		if isList(v.Type()) {
+			return formatList(v, opts)
+		}
+	case reflect.Map:
+		if isMap(v.Type()) {
+			return formatMap(v, opts)
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// textMarshaler returns v as an encoding.TextMarshaler, if either it or a pointer to it implements the interface.
+// Nil pointers are not returned, as they can't be marshaled.
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, false
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if !v.CanAddr() {
+		return nil, false
+	}
+	m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+	return m, ok
 }
@@ -1,8 +1,11 @@
 package configura_test
 
 import (
+	"errors"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ponrove/configura"
 	"github.com/stretchr/testify/assert"
@@ -117,6 +120,19 @@ func init() {
 	_ = os.Setenv("valid_float64_4", "9223372036854775808")
 	_ = os.Setenv("invalid_float64_1", "")
 	_ = os.Setenv("invalid_float64_2", "test")
+
+	// Time
+	_ = os.Setenv("valid_time_1", "2024-05-01T12:30:00+02:00")
+	_ = os.Setenv("valid_time_2", "2024-05-01 12:30:00")
+	_ = os.Setenv("valid_time_3", "2024-05-01")
+	_ = os.Setenv("invalid_time_1", "")
+	_ = os.Setenv("invalid_time_2", "yesterday")
+
+	// Duration
+	_ = os.Setenv("valid_duration_1", "30s")
+	_ = os.Setenv("valid_duration_2", "1h5m")
+	_ = os.Setenv("invalid_duration_1", "")
+	_ = os.Setenv("invalid_duration_2", "30")
 }
 
 func TestBool(t *testing.T) {
@@ -319,3 +335,54 @@ func TestFloat64(t *testing.T) {
 	invalid2 := configura.Float64("invalid_float64_2", 22.33)
 	assert.Equal(t, 22.33, invalid2)
 }
+
+func TestTime(t *testing.T) {
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid1 := configura.Time("valid_time_1", fallback)
+	assert.True(t, time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC).Equal(valid1))
+	valid2 := configura.Time("valid_time_2", fallback)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local), valid2)
+	valid3 := configura.Time("valid_time_3", fallback)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), valid3)
+	invalid1 := configura.Time("invalid_time_1", fallback)
+	assert.Equal(t, fallback, invalid1)
+	invalid2 := configura.Time("invalid_time_2", fallback)
+	assert.Equal(t, fallback, invalid2)
+}
+
+func TestDuration(t *testing.T) {
+	valid1 := configura.Duration("valid_duration_1", time.Minute)
+	assert.Equal(t, 30*time.Second, valid1)
+	valid2 := configura.Duration("valid_duration_2", time.Minute)
+	assert.Equal(t, time.Hour+5*time.Minute, valid2)
+	invalid1 := configura.Duration("invalid_duration_1", time.Minute)
+	assert.Equal(t, time.Minute, invalid1)
+	invalid2 := configura.Duration("invalid_duration_2", time.Minute)
+	assert.Equal(t, time.Minute, invalid2)
+}
+
+func TestLookupEnv(t *testing.T) {
+	t.Setenv("lookup_env_port", "80a")
+	t.Setenv("lookup_env_ok", "8080")
+
+	value, ok, err := configura.LookupEnv(configura.Variable[int]("lookup_env_ok"))
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, value)
+
+	value, ok, err = configura.LookupEnv(configura.Variable[int]("lookup_env_port"))
+	assert.True(t, ok)
+	assert.Equal(t, 0, value)
+	var parseErr *configura.ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, "lookup_env_port", parseErr.Key)
+		assert.Equal(t, "80a", parseErr.Value)
+		assert.Equal(t, "int", parseErr.Type)
+		assert.ErrorIs(t, parseErr, strconv.ErrSyntax)
+	}
+
+	value, ok, err = configura.LookupEnv(configura.Variable[int]("lookup_env_unset"))
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, value)
+}
@@ -0,0 +1,189 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError is returned when a configuration variable is set, but its value can't be converted to the type of the
+// variable. It carries everything needed to report the problem, and unwraps to the underlying conversion error, e.g.
+// a *strconv.NumError. It is also returned, without a value, for a variable whose type isn't supported at all, see
+// ErrUnsupportedType.
+type ParseError struct {
+	Key   string // Name of the configuration variable.
+	Value string // Raw value that failed to parse, empty if Redacted.
+	Type  string // Go type the value was parsed into, e.g. "uint8".
+	Err   error  // Underlying conversion error.
+
+	// Redacted is set when the value is a secret, which is left out of the error, see Sensitive.
+	Redacted bool
+
+	// Source is the name of the Source the value was read from, if known.
+	Source string
+	// Line and Column locate the value in the source, if it is a file that keeps track of positions.
+	Line, Column int
+}
+
+// Error implements the error interface for ParseError.
+func (e *ParseError) Error() string {
+	if e.Value == "" && e.Err == ErrUnsupportedType {
+		if e.Key == "" {
+			return fmt.Sprintf("configuration variables of type %s: %v", e.Type, e.Err)
+		}
+		return fmt.Sprintf("configuration variable %s of type %s: %v", e.Key, e.Type, e.Err)
+	}
+	value := fmt.Sprintf("value %q", e.Value)
+	if e.Redacted {
+		value = "secret value"
+	}
+	reason := e.reason()
+	if e.Source != "" {
+		source := e.Source
+		if e.Line > 0 {
+			source += ":" + strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column)
+		}
+		return fmt.Sprintf("invalid %s for configuration variable %s of type %s from %s: %v", value, e.Key, e.Type, source, reason)
+	}
+	return fmt.Sprintf("invalid %s for configuration variable %s of type %s: %v", value, e.Key, e.Type, reason)
+}
+
+// reason returns the underlying conversion error, without the value if it is repeated by the error.
+func (e *ParseError) reason() error {
+	return withoutValue(e.Err)
+}
+
+// withoutValue strips a *strconv.NumError, which repeats the value that failed to parse, down to its reason.
+func withoutValue(err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return numErr.Err
+	}
+	return err
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// redact leaves the value out of the *ParseError wrapped by err, and out of the *ElementError it wraps in turn, so a
+// secret isn't shown by the error.
+func redact(err error) {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return
+	}
+	parseErr.Value, parseErr.Redacted = "", true
+	var elemErr *ElementError
+	if errors.As(parseErr.Err, &elemErr) {
+		elemErr.Key, elemErr.Value, elemErr.Redacted = "", "", true
+	}
+}
+
+var _ error = (*ParseError)(nil)
+
+// ElementError is wrapped by a *ParseError when an element of a list, or an entry of a map, can't be parsed.
+// Elements of lists and entries of maps held by one key each are reported by a *ParseError for that key instead, e.g.
+// BROKERS_1 or LABEL_team.
+type ElementError struct {
+	Index int    // 0-based index of the element in the list.
+	Key   string // Key of the map entry, if the key could be parsed.
+	Value string // Raw value of the element, without its key for map entries.
+	Err   error  // Underlying conversion error.
+
+	// Redacted is set when the element is part of a secret, whose key and value are left out, see ParseError.
+	Redacted bool
+}
+
+// Error implements the error interface for ElementError.
+func (e *ElementError) Error() string {
+	if e.Redacted {
+		return fmt.Sprintf("element %d: %v", e.Index, withoutValue(e.Err))
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("value %q of key %q: %v", e.Value, e.Key, withoutValue(e.Err))
+	}
+	return fmt.Sprintf("element %d %q: %v", e.Index, e.Value, withoutValue(e.Err))
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+var _ error = (*ElementError)(nil)
+
+// LoadError is returned by LoadAll when one or more configuration variables can't be loaded. It collects the error of
+// every failed load, in the order of the loads, and works with errors.Is and errors.As, which look at each of them.
+type LoadError struct {
+	Errs []error // Errors of the failed loads, typically *ParseError values.
+}
+
+// Error implements the error interface for LoadError, listing the error of every failed load.
+func (e *LoadError) Error() string {
+	if len(e.Errs) == 1 {
+		return e.Errs[0].Error()
+	}
+	msg := strconv.Itoa(len(e.Errs)) + " configuration variables failed to load:"
+	for _, err := range e.Errs {
+		msg += "\n\t" + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed loads.
+func (e *LoadError) Unwrap() []error {
+	return e.Errs
+}
+
+// ParseErrors returns the *ParseError of every load that failed because its value couldn't be parsed.
+func (e *LoadError) ParseErrors() []*ParseError {
+	var parseErrs []*ParseError
+	for _, err := range e.Errs {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErrs = append(parseErrs, parseErr)
+		}
+	}
+	return parseErrs
+}
+
+var _ error = (*LoadError)(nil)
+
+// SyntaxError is returned when a configuration file can't be parsed. It reports where in the file the problem was
+// found.
+type SyntaxError struct {
+	Source string // Name of the file or source being parsed, if known.
+	Line   int    // 1-based line number of the problem, 0 if unknown.
+	Column int    // 1-based column of the problem, 0 if unknown.
+	Msg    string // Description of the problem.
+}
+
+// Error implements the error interface for SyntaxError.
+func (e *SyntaxError) Error() string {
+	var position []string
+	if e.Source != "" {
+		position = append(position, e.Source)
+	}
+	if e.Line > 0 {
+		position = append(position, strconv.Itoa(e.Line))
+		if e.Column > 0 {
+			position = append(position, strconv.Itoa(e.Column))
+		}
+	}
+	if len(position) == 0 {
+		return e.Msg
+	}
+	return strings.Join(position, ":") + ": " + e.Msg
+}
+
+var _ error = (*SyntaxError)(nil)
+
+// ErrTypeMismatch is wrapped by the ParseError returned when a value of a structured document, e.g. a JSON object or
+// boolean, can't be stored in a configuration variable of the requested type.
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// ErrUnsupportedType is wrapped by the ParseError returned when a value is loaded into a configuration variable of a
+// type that can't be parsed from text, see Variable.
+var ErrUnsupportedType = errors.New("unsupported type")
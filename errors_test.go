@@ -0,0 +1,72 @@
+package configura
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ParseErrorSuite tests the ParseError type.
+type ParseErrorSuite struct {
+	suite.Suite
+}
+
+func (s *ParseErrorSuite) TestError() {
+	_, numErr := strconv.ParseUint("300", 10, 8)
+	err := &ParseError{Key: "PORT", Value: "300", Type: "uint8", Err: numErr}
+	s.Equal(`invalid value "300" for configuration variable PORT of type uint8: value out of range`, err.Error())
+
+	err = &ParseError{Key: "KEY", Value: "x", Type: "custom", Err: errors.New("boom")}
+	s.Equal(`invalid value "x" for configuration variable KEY of type custom: boom`, err.Error())
+}
+
+func (s *ParseErrorSuite) TestRedacted() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", map[string]string{"API_TOKEN": "tok-80a", "API_TOKENS": "1,tok-2"}))
+
+	err := LoadEnvironment(cfg, Variable[int]("API_TOKEN"), 0, Sensitive())
+	s.EqualError(err, "invalid secret value for configuration variable API_TOKEN of type int from test: invalid syntax")
+	s.NotContains(err.Error(), "tok-80a")
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.True(parseErr.Redacted)
+	s.Empty(parseErr.Value)
+
+	err = LoadEnvironment(cfg, Variable[[]int]("API_TOKENS"), nil, Sensitive())
+	s.EqualError(err, "invalid secret value for configuration variable API_TOKENS of type []int from test: element 1: invalid syntax")
+	s.NotContains(err.Error(), "tok-2")
+}
+
+func (s *ParseErrorSuite) TestUnwrap() {
+	_, numErr := strconv.Atoi("80a")
+	var err error = &ParseError{Key: "PORT", Value: "80a", Type: "int", Err: numErr}
+
+	var target *strconv.NumError
+	s.Require().True(errors.As(err, &target))
+	s.Equal("80a", target.Num)
+	s.ErrorIs(err, strconv.ErrSyntax)
+}
+
+func (s *ParseErrorSuite) TestParse() {
+	_, err := parse[uint8]("PORT", "300", loadOptions{})
+	var parseErr *ParseError
+	s.Require().True(errors.As(err, &parseErr))
+	s.Equal("PORT", parseErr.Key)
+	s.Equal("300", parseErr.Value)
+	s.Equal("uint8", parseErr.Type)
+	s.ErrorIs(err, strconv.ErrRange)
+
+	value, err := parse[float32]("RATIO", "0.5", loadOptions{})
+	s.Require().NoError(err)
+	s.Equal(float32(0.5), value)
+
+	value2, err := parse[[]rune]("RUNES", "åäö", loadOptions{})
+	s.Require().NoError(err)
+	s.Equal([]rune("åäö"), value2)
+}
+
+func TestParseErrorSuite(t *testing.T) {
+	suite.Run(t, new(ParseErrorSuite))
+}
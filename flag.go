@@ -0,0 +1,203 @@
+package configura
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FlagSourceName is the name of the Source serving the values of command-line flags bound with BindFlag or
+// BindFlags, as reported by ConfigImpl.Origin.
+const FlagSourceName = "flags"
+
+// BindFlags registers a flag on fs for every configuration variable registered in config, see BindFlag. Variables
+// are usually loaded with LoadEnvironment first, so the help output shows the value taken from the environment, or
+// the fallback, as the default of each flag.
+//
+// An error is returned if the flag name derived from a variable is already defined on fs, e.g. because two
+// variables of different types share a name.
+func BindFlags(config *ConfigImpl, fs *flag.FlagSet) error {
+	r := config.load()
+	return errors.Join(
+		bindFlags(config, fs, r.regString),
+		bindFlags(config, fs, r.regInt),
+		bindFlags(config, fs, r.regInt8),
+		bindFlags(config, fs, r.regInt16),
+		bindFlags(config, fs, r.regInt32),
+		bindFlags(config, fs, r.regInt64),
+		bindFlags(config, fs, r.regUint),
+		bindFlags(config, fs, r.regUint8),
+		bindFlags(config, fs, r.regUint16),
+		bindFlags(config, fs, r.regUint32),
+		bindFlags(config, fs, r.regUint64),
+		bindFlags(config, fs, r.regUintptr),
+		bindFlags(config, fs, r.regBytes),
+		bindFlags(config, fs, r.regRunes),
+		bindFlags(config, fs, r.regFloat32),
+		bindFlags(config, fs, r.regFloat64),
+		bindFlags(config, fs, r.regBool),
+		bindFlags(config, fs, r.regTime),
+		bindFlags(config, fs, r.regDuration),
+		bindOtherFlags(config, fs, r.regOther),
+	)
+}
+
+func bindFlags[T constraint](config *ConfigImpl, fs *flag.FlagSet, values map[Variable[T]]T) error {
+	var errs []error
+	for key := range values {
+		errs = append(errs, BindFlag(config, fs, key, ""))
+	}
+	return errors.Join(errs...)
+}
+
+// bindOtherFlags binds the variables of types defined by the user, see registry.regOther.
+func bindOtherFlags(config *ConfigImpl, fs *flag.FlagSet, values map[any]any) error {
+	var errs []error
+	for key := range values {
+		errs = append(errs, key.(variable).bindFlag(config, fs))
+	}
+	return errors.Join(errs...)
+}
+
+// bindFlag binds the variable with BindFlag, with the default usage text.
+func (v Variable[T]) bindFlag(config *ConfigImpl, fs *flag.FlagSet) error {
+	return BindFlag(config, fs, v, "")
+}
+
+// BindFlag registers a flag on fs for the configuration variable key. The flag is named after the key in lower
+// case, with underscores and dots replaced by dashes, so DATABASE_URL is set with --database-url. Values are parsed
+// the same way as environment variables, with the options the variable was loaded with, and boolean flags may be set
+// without a value.
+//
+// The usage text, which defaults to the type of the variable, is followed by the environment variable the flag maps
+// to. The current value of the variable, or its zero value if it isn't registered, is shown as the default, unless
+// it is a secret: values loaded with the Sensitive option, or read from a secret file, a mounted directory or systemd
+// credentials, are never shown.
+//
+// Flags take precedence over every other source: setting a flag writes its value to config, and a source serving
+// the flags that were set is added in front of the sources of config, so LoadEnvironment keeps the flag value.
+func BindFlag[T constraint](config *ConfigImpl, fs *flag.FlagSet, key Variable[T], usage string) error {
+	if err := checkSupported[T](string(key)); err != nil {
+		return err
+	}
+	name := flagName(string(key))
+	if fs.Lookup(name) != nil {
+		return fmt.Errorf("flag -%s for configuration variable %s is already defined", name, key)
+	}
+
+	if usage == "" {
+		usage = fmt.Sprintf("`%s` value", key.typeName())
+	}
+	r := config.load()
+	value, _ := valueOf(r, key)
+	fs.Var(&flagValue[T]{
+		config: config,
+		source: config.flagSource(fs),
+		key:    key,
+		value:  value,
+		opts:   optionsOf(r, key),
+		secret: r.secrets[any(key)],
+	}, name, fmt.Sprintf("%s (env %s)", usage, key))
+	return nil
+}
+
+// flagName derives the name of the flag for a configuration variable from its key.
+func flagName(key string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(key))
+}
+
+// flagSource returns the source serving the flags of fs bound to c, adding it in front of the sources of c if it
+// isn't there yet.
+func (c *ConfigImpl) flagSource(fs *flag.FlagSet) *flagSource {
+	var src *flagSource
+	_ = c.update(func(r *registry) error {
+		for _, s := range r.sources {
+			if existing, ok := s.(*flagSource); ok && existing.fs == fs {
+				src = existing
+				return nil
+			}
+		}
+		src = &flagSource{fs: fs, values: make(map[string]string)}
+		r.sources = append([]Source{src}, r.sources...)
+		return nil
+	})
+	return src
+}
+
+// flagSource serves the raw values of the flags that were set on a flag set, keyed by configuration variable.
+type flagSource struct {
+	fs     *flag.FlagSet
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *flagSource) Name() string {
+	return FlagSourceName
+}
+
+func (s *flagSource) Lookup(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *flagSource) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Collect(maps.Keys(s.values))
+}
+
+func (s *flagSource) set(key, raw string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = raw
+}
+
+// flagValue implements flag.Value for a configuration variable, writing the values it is set to through to the
+// configuration.
+type flagValue[T constraint] struct {
+	config *ConfigImpl
+	source *flagSource
+	key    Variable[T]
+	value  T
+	opts   loadOptions
+	// secret hides the value, which is formatted as the zero value instead, so it isn't shown as the default.
+	secret bool
+}
+
+func (f *flagValue[T]) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.secret {
+		var zero T
+		return format(zero, f.opts)
+	}
+	return format(f.value, f.opts)
+}
+
+func (f *flagValue[T]) Set(raw string) error {
+	value, err := parse[T](string(f.key), raw, f.opts)
+	if err != nil {
+		return err.(*ParseError).reason()
+	}
+
+	f.value = value
+	f.source.set(string(f.key), raw)
+	return f.config.update(func(r *registry) error {
+		store(r, f.key, value)
+		r.origins = with(r.origins, any(f.key), FlagSourceName)
+		return nil
+	})
+}
+
+// IsBoolFlag allows boolean flags to be set without a value, e.g. --debug.
+func (f *flagValue[T]) IsBoolFlag() bool {
+	return reflect.TypeFor[T]().Kind() == reflect.Bool && !isTextUnmarshaler(reflect.TypeFor[T]())
+}
@@ -0,0 +1,175 @@
+package configura
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// FlagSuite tests binding configuration variables to command-line flags.
+type FlagSuite struct {
+	suite.Suite
+	config *ConfigImpl
+	fs     *flag.FlagSet
+}
+
+func (s *FlagSuite) SetupTest() {
+	s.config = NewConfigImpl()
+	s.config.SetSources(MapSource("environment", map[string]string{
+		"PORT":         "3000",
+		"DATABASE_URL": "postgres://env/db",
+	}))
+	s.fs = flag.NewFlagSet("app", flag.ContinueOnError)
+	s.fs.SetOutput(io.Discard)
+}
+
+func (s *FlagSuite) TestFlagsOverrideEnvironment() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("DATABASE_URL"), ""))
+	s.Require().NoError(BindFlags(s.config, s.fs))
+
+	s.Require().NoError(s.fs.Parse([]string{"--port=8080"}))
+	s.Equal(8080, s.config.Int("PORT"))
+	s.Equal(FlagSourceName, s.config.Origin(Variable[int]("PORT")))
+	s.Equal("postgres://env/db", s.config.String("DATABASE_URL"))
+	s.Equal("environment", s.config.Origin(Variable[string]("DATABASE_URL")))
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Equal(8080, s.config.Int("PORT"), "loading again must keep the flag value")
+}
+
+func (s *FlagSuite) TestTypes() {
+	s.Require().NoError(errors.Join(
+		BindFlag(s.config, s.fs, Variable[bool]("DEBUG"), ""),
+		BindFlag(s.config, s.fs, Variable[uint8]("RETRIES"), ""),
+		BindFlag(s.config, s.fs, Variable[float64]("RATIO"), ""),
+		BindFlag(s.config, s.fs, Variable[[]byte]("TOKEN"), ""),
+		BindFlag(s.config, s.fs, Variable[time.Time]("SINCE"), ""),
+	))
+
+	s.Require().NoError(s.fs.Parse([]string{"--debug", "--retries", "3", "-ratio=0.25", "--token=abc", "--since=2024-05-01"}))
+	s.True(s.config.Bool("DEBUG"))
+	s.Equal(uint8(3), s.config.Uint8("RETRIES"))
+	s.Equal(0.25, s.config.Float64("RATIO"))
+	s.Equal([]byte("abc"), s.config.Bytes("TOKEN"))
+	s.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), s.config.Time("SINCE"))
+}
+
+func (s *FlagSuite) TestDuration() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[time.Duration]("TIMEOUT"), 90*time.Second, DurationUnit(time.Second)))
+	s.Require().NoError(BindFlags(s.config, s.fs))
+	s.Equal("1m30s", s.fs.Lookup("timeout").DefValue)
+
+	s.Require().NoError(s.fs.Parse([]string{"--timeout=45"}))
+	s.Equal(45*time.Second, s.config.Duration("TIMEOUT"), "the flag must parse bare numbers like the variable")
+}
+
+func (s *FlagSuite) TestInvalidValue() {
+	var output bytes.Buffer
+	s.fs.SetOutput(&output)
+	s.Require().NoError(LoadEnvironment(s.config, Variable[uint8]("RETRIES"), 5))
+	s.Require().NoError(BindFlags(s.config, s.fs))
+
+	err := s.fs.Parse([]string{"--retries=300"})
+	s.EqualError(err, `invalid value "300" for flag -retries: value out of range, must be between 0 and 255`)
+	s.Contains(output.String(), "Usage of app:")
+	s.Equal(uint8(5), s.config.Uint8("RETRIES"))
+}
+
+func (s *FlagSuite) TestHelp() {
+	var output bytes.Buffer
+	s.fs.SetOutput(&output)
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(BindFlag(s.config, s.fs, Variable[string]("DATABASE_URL"), "database `url`"))
+	s.Require().NoError(BindFlags(s.config, s.fs))
+
+	s.fs.PrintDefaults()
+	s.Equal(""+
+		"  -database-url url\n"+
+		"    \tdatabase url (env DATABASE_URL)\n"+
+		"  -port int\n"+
+		"    \tint value (env PORT) (default 3000)\n", output.String())
+}
+
+func (s *FlagSuite) TestHelpHidesSecrets() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "db_pw"), []byte("hunter2"), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "api-key"), []byte("k3y"), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "pin"), []byte("1234"), 0o600))
+	s.T().Setenv(CredentialsDirectoryEnv, dir)
+	credentials, err := CredentialsSource(nil)
+	s.Require().NoError(err)
+	mounted, err := DirectorySource(dir, false)
+	s.Require().NoError(err)
+
+	s.config.SetSources(
+		SecretFiles(MapSource("environment", map[string]string{
+			"DB_PASSWORD_FILE": filepath.Join(dir, "db_pw"),
+			"TOKEN":            "t0ken",
+			"PORT":             "3000",
+		}), false),
+		credentials,
+		mounted,
+	)
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(s.config, Variable[string]("DB_PASSWORD"), ""),
+		LoadEnvironment(s.config, Variable[string]("API_KEY"), ""),
+		LoadEnvironment(s.config, Variable[string]("TOKEN"), "", Sensitive()),
+		LoadEnvironment(s.config, Variable[int]("PORT"), 80),
+	))
+	s.config.SetSources(mounted)
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PIN"), 0))
+
+	var output bytes.Buffer
+	s.fs.SetOutput(&output)
+	s.Require().NoError(BindFlags(s.config, s.fs))
+	s.fs.PrintDefaults()
+	for _, secret := range []string{"hunter2", "k3y", "t0ken", "1234"} {
+		s.NotContains(output.String(), secret)
+	}
+	s.Contains(output.String(), "(default 3000)", "values that aren't secrets are still shown")
+	s.Equal("", s.fs.Lookup("db-password").DefValue)
+	s.Equal("0", s.fs.Lookup("pin").DefValue)
+
+	s.Require().NoError(s.fs.Parse([]string{"--token=other"}))
+	s.Equal("other", s.config.String("TOKEN"))
+	s.Equal("hunter2", s.config.String("DB_PASSWORD"), "secrets are still loaded")
+}
+
+func (s *FlagSuite) TestFlagNames() {
+	testCases := map[string]string{
+		"PORT":             "port",
+		"DATABASE_URL":     "database-url",
+		"database.replica": "database-replica",
+	}
+	for key, expected := range testCases {
+		s.Equal(expected, flagName(key))
+	}
+}
+
+func (s *FlagSuite) TestAlreadyDefined() {
+	s.fs.String("port", "", "")
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+
+	s.EqualError(BindFlags(s.config, s.fs), "flag -port for configuration variable PORT is already defined")
+}
+
+func (s *FlagSuite) TestSingleFlagSource() {
+	s.Require().NoError(BindFlag(s.config, s.fs, Variable[int]("A"), ""))
+	s.Require().NoError(BindFlag(s.config, s.fs, Variable[int]("B"), ""))
+
+	sources := s.config.Sources()
+	s.Len(sources, 2)
+	s.Equal(FlagSourceName, sources[0].Name())
+}
+
+func TestFlagSuite(t *testing.T) {
+	suite.Run(t, new(FlagSuite))
+}
@@ -0,0 +1,88 @@
+package configura
+
+import "time"
+
+// Get returns the value of the configuration variable key, or the zero value of T if it isn't registered. It works
+// with any implementation of Config.
+func Get[T constraint](cfg Config, key Variable[T]) T {
+	value, _ := Lookup(cfg, key)
+	return value
+}
+
+// GetOr returns the value of the configuration variable key, or def if it isn't registered. Unlike Fallback, a
+// registered zero value is returned as is.
+func GetOr[T constraint](cfg Config, key Variable[T], def T) T {
+	if value, ok := Lookup(cfg, key); ok {
+		return value
+	}
+	return def
+}
+
+// Lookup returns the value of the configuration variable key, and whether it is registered, which tells a missing
+// key apart from one registered with the zero value. It works with any implementation of Config: registration is
+// checked with ConfigurationKeysRegistered, and the value read with the getter matching T. Variables of types
+// defined by the user, which Config has no getter for, are only found in a *ConfigImpl.
+func Lookup[T constraint](cfg Config, key Variable[T]) (T, bool) {
+	var zero T
+	switch c := cfg.(type) {
+	case nil:
+		return zero, false
+	case *ConfigImpl:
+		return valueOf(c.load(), key)
+	}
+
+	if cfg.ConfigurationKeysRegistered(key) != nil {
+		return zero, false
+	}
+	return getValue(cfg, key)
+}
+
+// getValue reads the value of key with the getter of cfg matching the type of the key. The boolean is false for
+// types defined by the user, which Config has no getter for.
+func getValue[T constraint](cfg Config, key Variable[T]) (T, bool) {
+	var value any
+	switch k := any(key).(type) {
+	case Variable[string]:
+		value = cfg.String(k)
+	case Variable[int]:
+		value = cfg.Int(k)
+	case Variable[int8]:
+		value = cfg.Int8(k)
+	case Variable[int16]:
+		value = cfg.Int16(k)
+	case Variable[int32]:
+		value = cfg.Int32(k)
+	case Variable[int64]:
+		value = cfg.Int64(k)
+	case Variable[uint]:
+		value = cfg.Uint(k)
+	case Variable[uint8]:
+		value = cfg.Uint8(k)
+	case Variable[uint16]:
+		value = cfg.Uint16(k)
+	case Variable[uint32]:
+		value = cfg.Uint32(k)
+	case Variable[uint64]:
+		value = cfg.Uint64(k)
+	case Variable[uintptr]:
+		value = cfg.Uintptr(k)
+	case Variable[[]byte]:
+		value = cfg.Bytes(k)
+	case Variable[[]rune]:
+		value = cfg.Runes(k)
+	case Variable[float32]:
+		value = cfg.Float32(k)
+	case Variable[float64]:
+		value = cfg.Float64(k)
+	case Variable[bool]:
+		value = cfg.Bool(k)
+	case Variable[time.Time]:
+		value = cfg.Time(k)
+	case Variable[time.Duration]:
+		value = cfg.Duration(k)
+	default:
+		var zero T
+		return zero, false
+	}
+	return value.(T), true
+}
@@ -0,0 +1,73 @@
+package configura
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// GetSuite tests the generic accessors Get, GetOr and Lookup.
+type GetSuite struct {
+	suite.Suite
+	config *ConfigImpl
+}
+
+// wrappedConfig is a Config implementation other than *ConfigImpl.
+type wrappedConfig struct {
+	Config
+}
+
+func (s *GetSuite) SetupTest() {
+	s.config = NewConfigImpl()
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[int]]int{"ZERO": 0, "PORT": 8080}))
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{"NAME": "service"}))
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[bool]]bool{"DEBUG": false}))
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[[]byte]][]byte{"TOKEN": []byte("abc")}))
+}
+
+func (s *GetSuite) TestImplementations() {
+	implementations := map[string]Config{
+		"ConfigImpl": s.config,
+		"Snapshot":   s.config.Snapshot(),
+		"Merge":      Merge(NewConfigImpl(), s.config),
+		"Wrapped":    wrappedConfig{s.config},
+	}
+
+	for name, cfg := range implementations {
+		s.Run(name, func() {
+			value, ok := Lookup(cfg, Variable[int]("ZERO"))
+			s.True(ok, "a registered zero value must be found")
+			s.Equal(0, value)
+
+			value, ok = Lookup(cfg, Variable[int]("MISSING"))
+			s.False(ok)
+			s.Equal(0, value)
+
+			_, ok = Lookup(cfg, Variable[int64]("PORT"))
+			s.False(ok, "variables are distinguished by type")
+
+			s.Equal(8080, Get(cfg, Variable[int]("PORT")))
+			s.Equal("service", Get(cfg, Variable[string]("NAME")))
+			s.Equal([]byte("abc"), Get(cfg, Variable[[]byte]("TOKEN")))
+			s.Equal("", Get(cfg, Variable[string]("MISSING")))
+
+			s.Equal(0, GetOr(cfg, Variable[int]("ZERO"), 42))
+			s.Equal(42, GetOr(cfg, Variable[int]("MISSING"), 42))
+			s.False(GetOr(cfg, Variable[bool]("DEBUG"), true))
+			s.True(GetOr(cfg, Variable[bool]("MISSING"), true))
+			s.Equal(time.Unix(0, 0), GetOr(cfg, Variable[time.Time]("MISSING"), time.Unix(0, 0)))
+		})
+	}
+}
+
+func (s *GetSuite) TestNilConfig() {
+	value, ok := Lookup[int](nil, "PORT")
+	s.False(ok)
+	s.Equal(0, value)
+	s.Equal(42, GetOr[int](nil, "PORT", 42))
+}
+
+func TestGetSuite(t *testing.T) {
+	suite.Run(t, new(GetSuite))
+}
@@ -2,10 +2,13 @@ module github.com/ponrove/configura
 
 go 1.24.3
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,97 @@
+package configura
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+// JSONFile reads the JSON document at path and returns a Source serving its values, named after the path. See
+// ParseJSON for how the document is mapped to configuration variables.
+func JSONFile(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, err := ParseJSON(path, f)
+	if err != nil {
+		return nil, err
+	}
+	return fileSource{Source: src, path: path, read: func() (Source, error) { return JSONFile(path) }}, nil
+}
+
+// LoadJSON reads the JSON document at path and adds it as the source with the lowest precedence to the
+// configuration, see LoadDotenv.
+func LoadJSON(config *ConfigImpl, path string) error {
+	src, err := JSONFile(path)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
+
+// ParseJSON parses the JSON document read from r, and returns a Source named name serving its values. The document
+// must be an object. Nested objects are flattened, so the value at the path database.url is served for both the
+// DATABASE_URL and database.url configuration variables, and array elements are served by index, e.g. BROKERS_0.
+//
+// Strings, numbers and booleans are parsed into the type of the configuration variable they are loaded into.
+// Numbers only load into numeric variables and booleans only into boolean variables, while any of them loads into
+// textual variables. Values that can't be stored, e.g. an object loaded into a Variable[int] or 300 loaded into a
+// Variable[uint8], are reported by LoadEnvironment as a *ParseError. Syntax errors are reported as a *SyntaxError.
+func ParseJSON(name string, r io.Reader) (Source, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, jsonError(name, content, err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		line, column := position(content, decoder.InputOffset())
+		return nil, &SyntaxError{Source: name, Line: line, Column: column, Msg: "unexpected data after top-level value"}
+	}
+
+	object, ok := document.(map[string]any)
+	if !ok {
+		return nil, &SyntaxError{Source: name, Line: 1, Column: 1, Msg: "JSON document must be an object"}
+	}
+
+	values := make(map[string]documentValue)
+	if err := flatten(name, values, nil, "_", object); err != nil {
+		return nil, err
+	}
+	return documentSource{name: name, values: values}, nil
+}
+
+// jsonError converts the syntax errors of the JSON decoder to a *SyntaxError with the position of the problem.
+func jsonError(name string, content []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset counts the offending byte, report the position of that byte.
+		line, column := position(content, max(syntaxErr.Offset-1, 0))
+		return &SyntaxError{Source: name, Line: line, Column: column, Msg: syntaxErr.Error()}
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		line, column := position(content, int64(len(content)))
+		return &SyntaxError{Source: name, Line: line, Column: column, Msg: "unexpected end of JSON input"}
+	}
+	return err
+}
+
+// position converts a byte offset into content to a 1-based line and column.
+func position(content []byte, offset int64) (line, column int) {
+	offset = min(offset, int64(len(content)))
+	before := content[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
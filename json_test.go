@@ -0,0 +1,172 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// JSONSuite tests loading configuration from JSON documents.
+type JSONSuite struct {
+	suite.Suite
+}
+
+const jsonDocument = `{
+	"port": 8080,
+	"debug": true,
+	"name": "service",
+	"ratio": 0.25,
+	"database": {
+		"url": "postgres://localhost/db",
+		"max-connections": "25",
+		"timeout": null
+	},
+	"brokers": ["kafka-1:9092", "kafka-2:9092"],
+	"small": 300,
+	"negative": -1,
+	"huge": 18446744073709551615
+}`
+
+func (s *JSONSuite) load(document string) *ConfigImpl {
+	src, err := ParseJSON("config.json", strings.NewReader(document))
+	s.Require().NoError(err)
+
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+	return cfg
+}
+
+func (s *JSONSuite) TestTypedValues() {
+	cfg := s.load(jsonDocument)
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[int]("PORT"), 0),
+		LoadEnvironment(cfg, Variable[uint16]("port"), 0),
+		LoadEnvironment(cfg, Variable[bool]("DEBUG"), false),
+		LoadEnvironment(cfg, Variable[string]("NAME"), ""),
+		LoadEnvironment(cfg, Variable[float64]("RATIO"), 0),
+		LoadEnvironment(cfg, Variable[string]("DATABASE_URL"), ""),
+		LoadEnvironment(cfg, Variable[int64]("database.max-connections"), 0),
+		LoadEnvironment(cfg, Variable[string]("BROKERS_1"), ""),
+		LoadEnvironment(cfg, Variable[uint64]("HUGE"), 0),
+		LoadEnvironment(cfg, Variable[string]("PORT"), ""),
+		LoadEnvironment(cfg, Variable[[]byte]("DEBUG"), nil),
+		LoadEnvironment(cfg, Variable[int]("DATABASE_TIMEOUT"), 30),
+	))
+
+	s.Equal(8080, cfg.Int("PORT"))
+	s.Equal(uint16(8080), cfg.Uint16("port"))
+	s.True(cfg.Bool("DEBUG"))
+	s.Equal("service", cfg.String("NAME"))
+	s.Equal(0.25, cfg.Float64("RATIO"))
+	s.Equal("postgres://localhost/db", cfg.String("DATABASE_URL"))
+	s.Equal(int64(25), cfg.Int64("database.max-connections"))
+	s.Equal("kafka-2:9092", cfg.String("BROKERS_1"))
+	s.Equal(uint64(18446744073709551615), cfg.Uint64("HUGE"))
+	s.Equal("8080", cfg.String("PORT"), "numbers are coerced into textual variables")
+	s.Equal([]byte("true"), cfg.Bytes("DEBUG"), "booleans are coerced into textual variables")
+	s.Equal(30, cfg.Int("DATABASE_TIMEOUT"), "null values are treated as unset")
+	s.Equal(OriginFallback, cfg.Origin(Variable[int]("DATABASE_TIMEOUT")))
+	s.Equal("config.json", cfg.Origin(Variable[int]("PORT")))
+}
+
+func (s *JSONSuite) TestLoadErrors() {
+	cfg := s.load(jsonDocument)
+
+	testCases := []struct {
+		name   string
+		load   func() error
+		value  string
+		target error
+	}{
+		{"Overflow", func() error { return LoadEnvironment(cfg, Variable[uint8]("SMALL"), 8) }, "300", strconv.ErrRange},
+		{"NegativeUnsigned", func() error { return LoadEnvironment(cfg, Variable[uint]("NEGATIVE"), 0) }, "-1", strconv.ErrSyntax},
+		{"FloatIntoInt", func() error { return LoadEnvironment(cfg, Variable[int]("RATIO"), 0) }, "0.25", strconv.ErrSyntax},
+		{"BoolIntoInt", func() error { return LoadEnvironment(cfg, Variable[int]("DEBUG"), 0) }, "true", ErrTypeMismatch},
+		{"NumberIntoBool", func() error { return LoadEnvironment(cfg, Variable[bool]("PORT"), false) }, "8080", ErrTypeMismatch},
+		{"ObjectIntoString", func() error { return LoadEnvironment(cfg, Variable[string]("DATABASE"), "") }, `{"max-connections":"25","timeout":null,"url":"postgres://localhost/db"}`, ErrTypeMismatch},
+		{"ArrayIntoString", func() error { return LoadEnvironment(cfg, Variable[string]("BROKERS"), "") }, `["kafka-1:9092","kafka-2:9092"]`, ErrTypeMismatch},
+		{"InvalidString", func() error { return LoadEnvironment(cfg, Variable[float32]("NAME"), 0) }, "service", strconv.ErrSyntax},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			err := tc.load()
+			var parseErr *ParseError
+			s.Require().ErrorAs(err, &parseErr)
+			s.Equal(tc.value, parseErr.Value)
+			s.Equal("config.json", parseErr.Source)
+			s.ErrorIs(err, tc.target)
+		})
+	}
+
+	s.Run("Message", func() {
+		err := LoadEnvironment(cfg, Variable[int]("DEBUG"), 0)
+		s.EqualError(err, `invalid value "true" for configuration variable DEBUG of type int from config.json: type mismatch: bool value`)
+	})
+}
+
+func (s *JSONSuite) TestSyntaxErrors() {
+	testCases := []struct {
+		name     string
+		document string
+		line     int
+		column   int
+	}{
+		{"InvalidCharacter", "{\n  \"port\": 80,\n  \"name\": x\n}", 3, 11},
+		{"Truncated", "{\n  \"port\": 80,", 2, 14},
+		{"NotAnObject", "[1, 2]", 1, 1},
+		{"TrailingData", "{}\n{}", 2, 2},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := ParseJSON("config.json", strings.NewReader(tc.document))
+			var syntaxErr *SyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.Equal("config.json", syntaxErr.Source)
+			s.Equal(tc.line, syntaxErr.Line)
+			s.Equal(tc.column, syntaxErr.Column)
+		})
+	}
+}
+
+func (s *JSONSuite) TestCollidingKeys() {
+	for range 20 {
+		_, err := ParseJSON("config.json", strings.NewReader(`{"database": {"url": "a"}, "database_url": "b"}`))
+		var syntaxErr *SyntaxError
+		s.Require().ErrorAs(err, &syntaxErr)
+		s.EqualError(err, `config.json: "database.url" and "database_url" are both loaded as DATABASE_URL`)
+	}
+
+	_, err := ParseJSON("config.json", strings.NewReader(`{"Port": 80, "port": 8080}`))
+	s.ErrorContains(err, `"Port" and "port" are both loaded as PORT`)
+	_, err = ParseJSON("config.json", strings.NewReader(`{"hosts": ["a"], "hosts_0": "b"}`))
+	s.ErrorContains(err, `"hosts.0" and "hosts_0" are both loaded as HOSTS_0`)
+}
+
+func (s *JSONSuite) TestJSONFile() {
+	path := filepath.Join(s.T().TempDir(), "config.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`{"json_file_port": 9090}`), 0o600))
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadJSON(cfg, path))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("JSON_FILE_PORT"), 0))
+	s.Equal(9090, cfg.Int("JSON_FILE_PORT"))
+	s.Equal(path, cfg.Origin(Variable[int]("JSON_FILE_PORT")))
+
+	s.T().Setenv("JSON_FILE_PORT", "7070")
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("JSON_FILE_PORT"), 0))
+	s.Equal(7070, cfg.Int("JSON_FILE_PORT"), "the environment takes precedence over the file")
+
+	s.ErrorIs(LoadJSON(cfg, filepath.Join(s.T().TempDir(), "missing.json")), os.ErrNotExist)
+}
+
+func TestJSONSuite(t *testing.T) {
+	suite.Run(t, new(JSONSuite))
+}
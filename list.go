@@ -0,0 +1,164 @@
+package configura
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultSeparator separates the elements of lists, unless changed with the Separator option.
+const DefaultSeparator = ","
+
+// Separator sets the separator between the elements of a list variable, e.g. Variable[[]string], which defaults to
+// DefaultSeparator. An empty separator keeps the default.
+func Separator(sep string) LoadOption {
+	return func(o *loadOptions) {
+		o.separator = sep
+	}
+}
+
+// isList reports whether values of type t are lists, which are slices parsed element by element. Slices of bytes
+// and runes are text, and slices implementing encoding.TextUnmarshaler, like net.IP, are parsed with it. Lists of
+// lists or maps aren't supported.
+func isList(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || isTextUnmarshaler(t) {
+		return false
+	}
+	switch t.Elem().Kind() {
+	case reflect.Uint8, reflect.Int32, reflect.Map:
+		return false
+	}
+	return !isList(t.Elem())
+}
+
+// parseList parses a delimited list, see splitList, into the slice v. Each element is parsed like a variable of the
+// element type; the first that can't be parsed is reported by an *ElementError.
+func parseList(v reflect.Value, raw string, opts loadOptions) error {
+	items, err := splitList(raw, opts.listSeparator())
+	if err != nil {
+		return err
+	}
+
+	list := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := parseValue(list.Index(i), item, opts); err != nil {
+			return &ElementError{Index: i, Value: item, Err: err}
+		}
+	}
+	v.Set(list)
+	return nil
+}
+
+// splitList splits a delimited list into its elements. Whitespace around elements is dropped, and an empty or blank
+// value is an empty list. Double quotes protect what they enclose, including separators and whitespace, and a
+// backslash escapes the character following it, so both "a,b" and a\,b are the single element a,b.
+func splitList(raw, sep string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var items []string
+	var item strings.Builder
+	// The part of the element between from and to was quoted or escaped, and isn't trimmed.
+	from, to := -1, -1
+	protect := func() {
+		if from < 0 {
+			from = item.Len()
+		}
+	}
+	quoted := false
+	for i := 0; i < len(raw); {
+		switch c := raw[i]; {
+		case c == '\\':
+			if i+1 == len(raw) {
+				return nil, errors.New("backslash at end of list")
+			}
+			protect()
+			r, size := utf8.DecodeRuneInString(raw[i+1:])
+			item.WriteRune(r)
+			to = item.Len()
+			i += 1 + size
+		case c == '"':
+			protect()
+			quoted = !quoted
+			to = item.Len()
+			i++
+		case quoted:
+			item.WriteByte(c)
+			to = item.Len()
+			i++
+		case strings.HasPrefix(raw[i:], sep):
+			items = append(items, trimElement(item.String(), from, to))
+			item.Reset()
+			from, to = -1, -1
+			i += len(sep)
+		default:
+			item.WriteByte(c)
+			i++
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote in list")
+	}
+	return append(items, trimElement(item.String(), from, to)), nil
+}
+
+// trimElement drops the whitespace around an element of a list, except for whitespace between from and to, which
+// was quoted or escaped. from is negative if nothing was.
+func trimElement(item string, from, to int) string {
+	if from < 0 {
+		return strings.TrimSpace(item)
+	}
+	return strings.TrimLeftFunc(item[:from], unicode.IsSpace) + item[from:to] + strings.TrimRightFunc(item[to:], unicode.IsSpace)
+}
+
+// formatList joins the elements of the list v with the separator, quoting those that wouldn't be split back into the
+// same element, see splitList.
+func formatList(v reflect.Value, opts loadOptions) string {
+	sep := opts.listSeparator()
+	items := make([]string, v.Len())
+	for i := range items {
+		item := formatValue(v.Index(i), opts)
+		if item == "" || item != strings.TrimSpace(item) || strings.Contains(item, sep) || strings.ContainsAny(item, `"\`) {
+			item = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+		}
+		items[i] = item
+	}
+	return strings.Join(items, sep)
+}
+
+// lookupIndexed retrieves a list held by a source as one key per element, KEY_0, KEY_1 and so on, into the slice v.
+// The keys of the elements of a document array are given as elements instead, since they are joined with the
+// separator of the document. The list ends at the first element missing from the source. If none is found, the list
+// is only reported as present if the source holds the key itself, e.g. as an empty array of a document. Elements
+// that can't be parsed are reported by a *ParseError for the key of the element.
+func lookupIndexed(source Source, key string, v reflect.Value, elements []string, present bool, opts loadOptions) (string, bool, error) {
+	list := reflect.MakeSlice(v.Type(), 0, 0)
+	origin := source.Name()
+	for i := 0; elements == nil || i < len(elements); i++ {
+		elemKey := key + "_" + strconv.Itoa(i)
+		if elements != nil {
+			elemKey = elements[i]
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elemOrigin, ok, err := lookupValue(source, elemKey, elem, opts)
+		if !ok {
+			break
+		}
+		if err != nil {
+			return elemOrigin, true, err
+		}
+		if i == 0 {
+			origin = elemOrigin
+		}
+		list = reflect.Append(list, elem)
+	}
+	if list.Len() == 0 && !present {
+		return "", false, nil
+	}
+	v.Set(list)
+	return origin, true, nil
+}
@@ -0,0 +1,195 @@
+package configura
+
+import (
+	"errors"
+	"flag"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ListSuite tests loading slice variables from delimited values and from one key per element.
+type ListSuite struct {
+	suite.Suite
+}
+
+func TestListSuite(t *testing.T) {
+	suite.Run(t, new(ListSuite))
+}
+
+func (s *ListSuite) load(values map[string]string) *ConfigImpl {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", values))
+	return cfg
+}
+
+func (s *ListSuite) TestSplitList() {
+	tests := []struct {
+		raw  string
+		sep  string
+		want []string
+	}{
+		{raw: "", sep: ",", want: nil},
+		{raw: "  ", sep: ",", want: nil},
+		{raw: "a", sep: ",", want: []string{"a"}},
+		{raw: "a, b ,c", sep: ",", want: []string{"a", "b", "c"}},
+		{raw: "a,,b", sep: ",", want: []string{"a", "", "b"}},
+		{raw: `"a,b",c`, sep: ",", want: []string{"a,b", "c"}},
+		{raw: `a\,b,c`, sep: ",", want: []string{"a,b", "c"}},
+		{raw: `" padded " , x`, sep: ",", want: []string{" padded ", "x"}},
+		{raw: `\ a`, sep: ",", want: []string{" a"}},
+		{raw: `say "hi, there"`, sep: ",", want: []string{"say hi, there"}},
+		{raw: `"a\"b",c\\d`, sep: ",", want: []string{`a"b`, `c\d`}},
+		{raw: `""`, sep: ",", want: []string{""}},
+		{raw: "a; b;c", sep: ";", want: []string{"a", "b", "c"}},
+		{raw: "a::b:c", sep: "::", want: []string{"a", "b:c"}},
+		{raw: "a b  c", sep: " ", want: []string{"a", "b", "", "c"}},
+	}
+	for _, tt := range tests {
+		got, err := splitList(tt.raw, tt.sep)
+		s.NoError(err, tt.raw)
+		s.Equal(tt.want, got, tt.raw)
+	}
+
+	_, err := splitList(`"a,b`, ",")
+	s.EqualError(err, "unterminated quote in list")
+	_, err = splitList(`a\`, ",")
+	s.EqualError(err, "backslash at end of list")
+}
+
+func (s *ListSuite) TestDelimited() {
+	cfg := s.load(map[string]string{
+		"ORIGINS":  `https://a.example, https://b.example`,
+		"PORTS":    "80,443",
+		"TIMEOUTS": "1s;1m;1h",
+		"PEERS":    "10.0.0.1,::1",
+		"EMPTY":    "",
+	})
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[[]string]("ORIGINS"), nil),
+		LoadEnvironment(cfg, Variable[[]int]("PORTS"), nil),
+		LoadEnvironment(cfg, Variable[[]time.Duration]("TIMEOUTS"), nil, Separator(";")),
+		LoadEnvironment(cfg, Variable[[]netip.Addr]("PEERS"), nil),
+		LoadEnvironment(cfg, Variable[[]string]("EMPTY"), []string{"fallback"}),
+	))
+
+	s.Equal([]string{"https://a.example", "https://b.example"}, Get(cfg, Variable[[]string]("ORIGINS")))
+	s.Equal([]int{80, 443}, Get(cfg, Variable[[]int]("PORTS")))
+	s.Equal([]time.Duration{time.Second, time.Minute, time.Hour}, Get(cfg, Variable[[]time.Duration]("TIMEOUTS")))
+	s.Equal([]netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.IPv6Loopback()}, Get(cfg, Variable[[]netip.Addr]("PEERS")))
+	s.Empty(Get(cfg, Variable[[]string]("EMPTY")), "an empty value is an empty list, not a missing one")
+	s.Equal("test", cfg.Origin(Variable[[]string]("EMPTY")))
+}
+
+func (s *ListSuite) TestInvalidElement() {
+	cfg := s.load(map[string]string{"PORTS": "80,http,443"})
+
+	err := LoadEnvironment(cfg, Variable[[]uint16]("PORTS"), []uint16{8080})
+	s.EqualError(err, `invalid value "80,http,443" for configuration variable PORTS of type []uint16 from test: element 1 "http": invalid syntax`)
+	var elemErr *ElementError
+	s.Require().ErrorAs(err, &elemErr)
+	s.Equal(1, elemErr.Index)
+	s.Equal("http", elemErr.Value)
+	s.Equal([]uint16{8080}, Get(cfg, Variable[[]uint16]("PORTS")))
+
+	cfg = s.load(map[string]string{"QUOTED": `"a,b`})
+	s.ErrorContains(LoadEnvironment(cfg, Variable[[]string]("QUOTED"), nil), "unterminated quote in list")
+}
+
+func (s *ListSuite) TestIndexed() {
+	cfg := s.load(map[string]string{
+		"BROKERS_0": "kafka-0:9092",
+		"BROKERS_1": "kafka-1:9092",
+		"BROKERS_3": "ignored, as index 2 is missing",
+		"LIMITS_0":  "10",
+		"LIMITS_1":  "ten",
+	})
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]string]("BROKERS"), nil))
+	s.Equal([]string{"kafka-0:9092", "kafka-1:9092"}, Get(cfg, Variable[[]string]("BROKERS")))
+	s.Equal("test", cfg.Origin(Variable[[]string]("BROKERS")))
+
+	err := LoadEnvironment(cfg, Variable[[]int]("LIMITS"), []int{1})
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("LIMITS_1", parseErr.Key)
+	s.Equal("int", parseErr.Type)
+	s.Equal([]int{1}, Get(cfg, Variable[[]int]("LIMITS")))
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]string]("MISSING"), []string{"fallback"}))
+	s.Equal([]string{"fallback"}, Get(cfg, Variable[[]string]("MISSING")))
+	s.Equal(OriginFallback, cfg.Origin(Variable[[]string]("MISSING")))
+}
+
+func (s *ListSuite) TestDelimitedTakesPrecedence() {
+	cfg := s.load(map[string]string{"BROKERS": "a,b", "BROKERS_0": "c"})
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]string]("BROKERS"), nil))
+	s.Equal([]string{"a", "b"}, Get(cfg, Variable[[]string]("BROKERS")))
+}
+
+func (s *ListSuite) TestDocument() {
+	src, err := ParseJSON("config.json", strings.NewReader(`{
+		"brokers": ["kafka-0:9092", "kafka-1:9092"],
+		"ports": [80, 443],
+		"origins": "a.example,b.example",
+		"none": [],
+		"mixed": [1, {"port": 2}]
+	}`))
+	s.Require().NoError(err)
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[[]string]("BROKERS"), nil),
+		LoadEnvironment(cfg, Variable[[]int]("PORTS"), nil),
+		LoadEnvironment(cfg, Variable[[]string]("ORIGINS"), nil),
+		LoadEnvironment(cfg, Variable[[]string]("NONE"), []string{"fallback"}),
+	))
+	s.Equal([]string{"kafka-0:9092", "kafka-1:9092"}, Get(cfg, Variable[[]string]("BROKERS")))
+	s.Equal([]int{80, 443}, Get(cfg, Variable[[]int]("PORTS")))
+	s.Equal([]string{"a.example", "b.example"}, Get(cfg, Variable[[]string]("ORIGINS")))
+	s.Empty(Get(cfg, Variable[[]string]("NONE")), "an empty array is an empty list")
+
+	err = LoadEnvironment(cfg, Variable[[]int]("MIXED"), nil)
+	s.ErrorIs(err, ErrTypeMismatch)
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("MIXED_1", parseErr.Key)
+}
+
+func (s *ListSuite) TestUnsupported() {
+	cfg := s.load(map[string]string{"NESTED": "a,b"})
+	s.ErrorIs(LoadEnvironment(cfg, Variable[[][]string]("NESTED"), nil), ErrUnsupportedType)
+}
+
+func (s *ListSuite) TestFlag() {
+	cfg := s.load(map[string]string{"TAGS": `a,"b,c", d `})
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]string]("TAGS"), nil))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]int]("SIZES"), []int{1, 2}, Separator(":")))
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal(`a,"b,c",d`, fs.Lookup("tags").DefValue)
+	s.Equal("1:2", fs.Lookup("sizes").DefValue)
+
+	s.Require().NoError(fs.Parse([]string{"--tags=x,y", "--sizes=3:4:5"}))
+	s.Equal([]string{"x", "y"}, Get(cfg, Variable[[]string]("TAGS")))
+	s.Equal([]int{3, 4, 5}, Get(cfg, Variable[[]int]("SIZES")))
+}
+
+func (s *ListSuite) TestFormatList() {
+	opts := newLoadOptions(nil)
+	for _, list := range [][]string{{"a", "b"}, {""}, {" padded ", `quote"`, `back\slash`, "a,b"}, {}} {
+		raw := format(list, opts)
+		parsed, err := parse[[]string]("LIST", raw, opts)
+		s.Require().NoError(err, raw)
+		s.Equal(len(list), len(parsed), raw)
+		for i := range list {
+			s.Equal(list[i], parsed[i], raw)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrDuplicateKey is wrapped by the error returned when a map variable is given the same key twice.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// isMap reports whether values of type t are maps, which are parsed entry by entry. Keys and values may be of any
+// type that isn't a list or a map.
+func isMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || isTextUnmarshaler(t) {
+		return false
+	}
+	for _, part := range []reflect.Type{t.Key(), t.Elem()} {
+		if part.Kind() == reflect.Map || isList(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMap parses a delimited list of key=value entries, see splitList, into the map v. Entries are split at their
+// first '=', so keys can't contain one. Keys and values are parsed like variables of their type; the first entry that
+// can't be parsed, or repeats a key, is reported by an *ElementError.
+func parseMap(v reflect.Value, raw string, opts loadOptions) error {
+	items, err := splitList(raw, opts.listSeparator())
+	if err != nil {
+		return err
+	}
+
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(items))
+	indexes := make(map[any]int, len(items))
+	for i, item := range items {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return &ElementError{Index: i, Value: item, Err: errors.New("missing '=' between key and value")}
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		key := reflect.New(t.Key()).Elem()
+		if err := parseValue(key, name, opts); err != nil {
+			return &ElementError{Index: i, Value: item, Err: err}
+		}
+		if first, ok := indexes[key.Interface()]; ok {
+			return &ElementError{Index: i, Key: name, Value: value, Err: fmt.Errorf("%w, also set by element %d", ErrDuplicateKey, first)}
+		}
+		indexes[key.Interface()] = i
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := parseValue(elem, value, opts); err != nil {
+			return &ElementError{Index: i, Key: name, Value: value, Err: err}
+		}
+		m.SetMapIndex(key, elem)
+	}
+	v.Set(m)
+	return nil
+}
+
+// formatMap joins the entries of the map v, sorted by their formatted key, as key=value with the separator, quoting
+// those that wouldn't be split back into the same entry, see formatList.
+func formatMap(v reflect.Value, opts loadOptions) string {
+	items := make([]string, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		items = append(items, formatValue(iter.Key(), opts)+"="+formatValue(iter.Value(), opts))
+	}
+	slices.Sort(items)
+
+	list := reflect.New(reflect.TypeFor[[]string]()).Elem()
+	list.Set(reflect.ValueOf(items))
+	return formatList(list, opts)
+}
+
+// keyLister is implemented by sources that can enumerate the keys they hold, which allows maps to be collected from
+// the keys sharing a prefix.
+type keyLister interface {
+	keys() []string
+}
+
+// lookupPrefixed retrieves a map held by a source as one key per entry, made of the key of the map variable, an
+// underscore and the key of the entry, e.g. LABEL_team, into the map v. Sources that can't enumerate their keys don't
+// hold any such map.
+func lookupPrefixed(source Source, key string, v reflect.Value, opts loadOptions) (string, bool, error) {
+	lister, ok := source.(keyLister)
+	if !ok {
+		return "", false, nil
+	}
+
+	prefix := key + "_"
+	entries := make(map[string]string)
+	for _, k := range lister.keys() {
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			entries[name] = k
+		}
+	}
+	if len(entries) == 0 {
+		return "", false, nil
+	}
+	return lookupEntries(source, v, entries, opts)
+}
+
+// lookupEntries retrieves the entries of a map held by a source into the map v. entries maps the key of each entry,
+// as written, to the key holding its value in the source. Entries that can't be parsed, or whose key is the same as
+// another one once parsed, e.g. 1 and 01 for a map keyed by int, are reported by a *ParseError for the key holding
+// the entry.
+func lookupEntries(source Source, v reflect.Value, entries map[string]string, opts loadOptions) (string, bool, error) {
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(entries))
+	origin := source.Name()
+	seen := make(map[any]string, len(entries))
+	for _, name := range slices.Sorted(maps.Keys(entries)) {
+		sourceKey := entries[name]
+		key := reflect.New(t.Key()).Elem()
+		if err := parseValue(key, name, opts); err != nil {
+			return origin, true, &ParseError{Key: sourceKey, Value: name, Type: t.Key().String(), Err: err, Source: origin}
+		}
+		if other, ok := seen[key.Interface()]; ok {
+			err := fmt.Errorf("%w, also set by %s", ErrDuplicateKey, other)
+			return origin, true, &ParseError{Key: sourceKey, Value: name, Type: t.Key().String(), Err: err, Source: origin}
+		}
+		seen[key.Interface()] = sourceKey
+
+		elem := reflect.New(t.Elem()).Elem()
+		elemOrigin, ok, err := lookupValue(source, sourceKey, elem, opts)
+		if err != nil {
+			return elemOrigin, true, err
+		}
+		if ok {
+			m.SetMapIndex(key, elem)
+		}
+	}
+	v.Set(m)
+	return origin, true, nil
+}
@@ -0,0 +1,202 @@
+package configura
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// MapSuite tests loading map variables from key=value lists and from one key per entry.
+type MapSuite struct {
+	suite.Suite
+}
+
+func TestMapSuite(t *testing.T) {
+	suite.Run(t, new(MapSuite))
+}
+
+func (s *MapSuite) load(values map[string]string) *ConfigImpl {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("test", values))
+	return cfg
+}
+
+func (s *MapSuite) TestDelimited() {
+	cfg := s.load(map[string]string{
+		"HEADERS": `X-Team=core, X-Note="a, b", X-Eq=a=b`,
+		"LIMITS":  "free=10;pro=1000",
+		"EMPTY":   "",
+	})
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[map[string]string]("HEADERS"), nil),
+		LoadEnvironment(cfg, Variable[map[string]int]("LIMITS"), nil, Separator(";")),
+		LoadEnvironment(cfg, Variable[map[string]bool]("EMPTY"), map[string]bool{"fallback": true}),
+	))
+
+	s.Equal(map[string]string{"X-Team": "core", "X-Note": "a, b", "X-Eq": "a=b"}, Get(cfg, Variable[map[string]string]("HEADERS")))
+	s.Equal(map[string]int{"free": 10, "pro": 1000}, Get(cfg, Variable[map[string]int]("LIMITS")))
+	s.Empty(Get(cfg, Variable[map[string]bool]("EMPTY")), "an empty value is an empty map, not a missing one")
+}
+
+func (s *MapSuite) TestInvalidEntry() {
+	cfg := s.load(map[string]string{
+		"LIMITS":    "free=10,pro=lots",
+		"MALFORMED": "free=10,pro",
+		"REPEATED":  "free=10,pro=20,free=30",
+		"KEYED":     "1=a,x=b",
+	})
+
+	err := LoadEnvironment(cfg, Variable[map[string]int]("LIMITS"), map[string]int{"free": 1})
+	s.EqualError(err, `invalid value "free=10,pro=lots" for configuration variable LIMITS of type map[string]int from test: value "lots" of key "pro": invalid syntax`)
+	var elemErr *ElementError
+	s.Require().ErrorAs(err, &elemErr)
+	s.Equal(1, elemErr.Index)
+	s.Equal("pro", elemErr.Key)
+	s.Equal(map[string]int{"free": 1}, Get(cfg, Variable[map[string]int]("LIMITS")))
+
+	s.ErrorContains(LoadEnvironment(cfg, Variable[map[string]int]("MALFORMED"), nil), `element 1 "pro": missing '=' between key and value`)
+
+	err = LoadEnvironment(cfg, Variable[map[string]int]("REPEATED"), nil)
+	s.ErrorIs(err, ErrDuplicateKey)
+	s.ErrorContains(err, `value "30" of key "free": duplicate key, also set by element 0`)
+
+	err = LoadEnvironment(cfg, Variable[map[int]string]("KEYED"), nil)
+	s.ErrorContains(err, `element 1 "x=b": invalid syntax`)
+}
+
+func (s *MapSuite) TestPrefixed() {
+	cfg := s.load(map[string]string{
+		"LABEL_team":   "core",
+		"LABEL_tier":   "1",
+		"LABELS":       "not collected, as the prefix is LABEL_",
+		"QUOTA_free":   "10",
+		"QUOTA_pro":    "lots",
+		"RETRIES_1":    "a",
+		"RETRIES_01":   "b",
+		"UNRELATED_ok": "x",
+	})
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("LABEL"), nil))
+	s.Equal(map[string]string{"team": "core", "tier": "1"}, Get(cfg, Variable[map[string]string]("LABEL")))
+	s.Equal("test", cfg.Origin(Variable[map[string]string]("LABEL")))
+
+	err := LoadEnvironment(cfg, Variable[map[string]int]("QUOTA"), nil)
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("QUOTA_pro", parseErr.Key)
+	s.Equal("int", parseErr.Type)
+
+	err = LoadEnvironment(cfg, Variable[map[int]string]("RETRIES"), nil)
+	s.ErrorIs(err, ErrDuplicateKey)
+	s.EqualError(err, `invalid value "1" for configuration variable RETRIES_1 of type int from test: duplicate key, also set by RETRIES_01`)
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("MISSING"), map[string]string{"a": "b"}))
+	s.Equal(map[string]string{"a": "b"}, Get(cfg, Variable[map[string]string]("MISSING")))
+	s.Equal(OriginFallback, cfg.Origin(Variable[map[string]string]("MISSING")))
+}
+
+func (s *MapSuite) TestEnvironment() {
+	s.T().Setenv("MAP_SUITE_LABEL_team", "core")
+	s.T().Setenv("MAP_SUITE_LABEL_env", "prod")
+	cfg := NewConfigImpl()
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("MAP_SUITE_LABEL"), nil))
+	s.Equal(map[string]string{"team": "core", "env": "prod"}, Get(cfg, Variable[map[string]string]("MAP_SUITE_LABEL")))
+
+	s.T().Setenv("MAP_SUITE_LABEL", "only=this")
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("MAP_SUITE_LABEL"), nil))
+	s.Equal(map[string]string{"only": "this"}, Get(cfg, Variable[map[string]string]("MAP_SUITE_LABEL")), "the variable itself takes precedence")
+}
+
+func (s *MapSuite) TestSecretFiles() {
+	path := filepath.Join(s.T().TempDir(), "token")
+	s.Require().NoError(os.WriteFile(path, []byte("s3cret\n"), 0o600))
+	cfg := NewConfigImpl()
+	cfg.SetSources(SecretFiles(MapSource("test", map[string]string{
+		"TOKEN_ci":          "plain",
+		"TOKEN_deploy_FILE": path,
+	}), true))
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("TOKEN"), nil))
+	s.Equal(map[string]string{"ci": "plain", "deploy": "s3cret"}, Get(cfg, Variable[map[string]string]("TOKEN")))
+}
+
+func (s *MapSuite) TestDocument() {
+	src, err := ParseYAML("config.yaml", strings.NewReader(`
+headers:
+  X-Team: core
+  X-Retries: 3
+limits:
+  free: 10
+  pro:
+    monthly: 1000
+none: {}
+`))
+	s.Require().NoError(err)
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[map[string]string]("HEADERS"), nil),
+		LoadEnvironment(cfg, Variable[map[string]int]("NONE"), map[string]int{"fallback": 1}),
+	))
+	s.Equal(map[string]string{"X-Team": "core", "X-Retries": "3"}, Get(cfg, Variable[map[string]string]("HEADERS")), "keys keep their case")
+	s.Empty(Get(cfg, Variable[map[string]int]("NONE")), "an empty object is an empty map")
+
+	err = LoadEnvironment(cfg, Variable[map[string]int]("LIMITS"), nil)
+	s.ErrorIs(err, ErrTypeMismatch)
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("LIMITS_PRO", parseErr.Key)
+	s.Equal(8, parseErr.Line)
+
+	src, err = ParseJSON("config.json", strings.NewReader(`{"weights": {"a": 0.5, "b": 1.5}}`))
+	s.Require().NoError(err)
+	cfg.SetSources(src)
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]float64]("WEIGHTS"), nil))
+	s.Equal(map[string]float64{"a": 0.5, "b": 1.5}, Get(cfg, Variable[map[string]float64]("WEIGHTS")))
+}
+
+func (s *MapSuite) TestUnsupported() {
+	cfg := s.load(map[string]string{"NESTED": "a=b"})
+	s.ErrorIs(LoadEnvironment(cfg, Variable[map[string][]string]("NESTED"), nil), ErrUnsupportedType)
+	s.ErrorIs(LoadEnvironment(cfg, Variable[[]map[string]string]("NESTED"), nil), ErrUnsupportedType)
+}
+
+func (s *MapSuite) TestFlag() {
+	cfg := s.load(map[string]string{"LABEL": "b=2, a=1"})
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]int]("LABEL"), nil))
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	s.Require().NoError(BindFlags(cfg, fs))
+	s.Equal("a=1,b=2", fs.Lookup("label").DefValue)
+
+	s.Require().NoError(fs.Parse([]string{"--label=c=3"}))
+	s.Equal(map[string]int{"c": 3}, Get(cfg, Variable[map[string]int]("LABEL")))
+}
+
+func (s *MapSuite) TestReload() {
+	values := map[string]string{"LABEL": ""}
+	cfg := NewConfigImpl()
+	cfg.SetSources(SourceFunc("test", func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("LABEL"), nil))
+
+	var events []ReloadEvent
+	defer cfg.OnReload(func(event ReloadEvent) { events = append(events, event) })()
+	s.Require().NoError(cfg.Reload())
+	values["LABEL"] = "team=core"
+	s.Require().NoError(cfg.Reload())
+
+	s.Require().Len(events, 2)
+	s.Empty(events[0].Changed, "empty maps are equal")
+	s.Equal([]string{"LABEL"}, events[1].Changed)
+}
@@ -0,0 +1,85 @@
+package configura
+
+import "time"
+
+// LoadOption customizes how the raw value of a configuration variable is parsed, see LoadEnvironment. Options that
+// don't apply to the type of the variable are ignored.
+type LoadOption func(*loadOptions)
+
+// loadOptions holds the settings of the LoadOption values a variable was loaded with.
+type loadOptions struct {
+	durationUnit time.Duration // Unit of bare numbers in time.Duration values, 0 if they aren't accepted.
+	timeLayouts  []string      // Layouts accepted for time.Time values in addition to timeLayouts.
+	separator    string        // Separator between the elements of lists, DefaultSeparator if empty.
+	encoding     Encoding      // Encoding of []byte values.
+	length       int           // Length of []byte values once decoded, 0 if it isn't checked.
+	literals     bool          // Whether integers may be written as Go integer literals.
+	sensitive    bool          // Whether the value is a secret, whatever its source.
+}
+
+// newLoadOptions applies opts to the default settings.
+func newLoadOptions(opts []LoadOption) loadOptions {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// redacted reports whether parse errors leave the value out, see ParseError.Redacted: it is a secret, or a byte
+// string decoded with BytesEncoding or checked with ExactLength, which is usually a key.
+func (o loadOptions) redacted() bool {
+	return o.sensitive || o.encoding != EncodingRaw || o.length > 0
+}
+
+// listSeparator returns the separator between the elements of lists.
+func (o loadOptions) listSeparator() string {
+	if o.separator == "" {
+		return DefaultSeparator
+	}
+	return o.separator
+}
+
+// integerBase returns the base integers are parsed in, as understood by strconv.ParseInt.
+func (o loadOptions) integerBase() int {
+	if o.literals {
+		return 0
+	}
+	return 10
+}
+
+// IntegerLiterals makes an integer variable accept Go integer literals, so 0x1F, 0o755, 0b1010 and 1_000_000 are
+// valid, for every integer type. As in Go, a leading 0 makes the number octal, so 0755 is 493 rather than 755.
+// Without it, integers are decimal.
+func IntegerLiterals() LoadOption {
+	return func(o *loadOptions) {
+		o.literals = true
+	}
+}
+
+// Sensitive marks the value of a variable as a secret, like a password or an API key, so it isn't shown as the default
+// of its command-line flag, nor by the *ParseError reporting that it can't be parsed. Values read from secret files,
+// mounted directories and systemd credentials are treated as secrets without it.
+func Sensitive() LoadOption {
+	return func(o *loadOptions) {
+		o.sensitive = true
+	}
+}
+
+// DurationUnit makes a time.Duration variable accept bare numbers, which are interpreted in unit, so with
+// DurationUnit(time.Second) both 30 and 30s are thirty seconds. Fractions are allowed, e.g. 1.5. Without it, a
+// duration must carry its units, like 30s or 1h5m.
+func DurationUnit(unit time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.durationUnit = unit
+	}
+}
+
+// TimeLayouts makes a time.Time variable accept values in the given layouts, as understood by time.Parse, in addition
+// to RFC 3339 timestamps and local dates and times. The layouts are tried in order, after the default ones. Values
+// without a time zone are interpreted in the local time zone.
+func TimeLayouts(layouts ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.timeLayouts = append(o.timeLayouts, layouts...)
+	}
+}
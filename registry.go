@@ -0,0 +1,185 @@
+package configura
+
+import (
+	"maps"
+	"time"
+)
+
+// registry is an immutable snapshot of every configuration value held by a ConfigImpl. Once a registry has been
+// published through ConfigImpl.update it must never be modified again, which is what allows readers to access it
+// without taking any locks. Writers copy the registry, replace the maps they change with modified copies and publish
+// the result as a new snapshot.
+type registry struct {
+	regString   map[Variable[string]]string
+	regInt      map[Variable[int]]int
+	regInt8     map[Variable[int8]]int8
+	regInt16    map[Variable[int16]]int16
+	regInt32    map[Variable[int32]]int32
+	regInt64    map[Variable[int64]]int64
+	regUint     map[Variable[uint]]uint
+	regUint8    map[Variable[uint8]]uint8
+	regUint16   map[Variable[uint16]]uint16
+	regUint32   map[Variable[uint32]]uint32
+	regUint64   map[Variable[uint64]]uint64
+	regUintptr  map[Variable[uintptr]]uintptr
+	regBytes    map[Variable[[]byte]][]byte
+	regRunes    map[Variable[[]rune]][]rune
+	regFloat32  map[Variable[float32]]float32
+	regFloat64  map[Variable[float64]]float64
+	regBool     map[Variable[bool]]bool
+	regTime     map[Variable[time.Time]]time.Time
+	regDuration map[Variable[time.Duration]]time.Duration
+	// regOther holds the variables of types defined by the user, e.g. type Port uint16, keyed by the variable.
+	regOther map[any]any
+
+	// sources are the sources LoadEnvironment draws values from, in order of precedence.
+	sources []Source
+	// origins holds the name of the source that supplied each loaded variable, keyed by the variable.
+	origins map[any]string
+	// secrets records whether the value of each loaded variable is a secret, keyed by the variable, see Sensitive.
+	secrets map[any]bool
+	// bindings records how each variable was loaded with LoadEnvironment, keyed by the variable, see Reload.
+	bindings map[any]binding
+}
+
+// newRegistry returns an empty registry with all maps initialized.
+func newRegistry() *registry {
+	return &registry{
+		regString:   make(map[Variable[string]]string),
+		regInt:      make(map[Variable[int]]int),
+		regInt8:     make(map[Variable[int8]]int8),
+		regInt16:    make(map[Variable[int16]]int16),
+		regInt32:    make(map[Variable[int32]]int32),
+		regInt64:    make(map[Variable[int64]]int64),
+		regUint:     make(map[Variable[uint]]uint),
+		regUint8:    make(map[Variable[uint8]]uint8),
+		regUint16:   make(map[Variable[uint16]]uint16),
+		regUint32:   make(map[Variable[uint32]]uint32),
+		regUint64:   make(map[Variable[uint64]]uint64),
+		regUintptr:  make(map[Variable[uintptr]]uintptr),
+		regBytes:    make(map[Variable[[]byte]][]byte),
+		regRunes:    make(map[Variable[[]rune]][]rune),
+		regFloat32:  make(map[Variable[float32]]float32),
+		regFloat64:  make(map[Variable[float64]]float64),
+		regBool:     make(map[Variable[bool]]bool),
+		regTime:     make(map[Variable[time.Time]]time.Time),
+		regDuration: make(map[Variable[time.Duration]]time.Duration),
+		regOther:    make(map[any]any),
+		sources:     []Source{Environment},
+		origins:     make(map[any]string),
+		secrets:     make(map[any]bool),
+		bindings:    make(map[any]binding),
+	}
+}
+
+// emptyRegistry is served to readers of a ConfigImpl that has not been created through NewConfigImpl.
+var emptyRegistry = newRegistry()
+
+// with returns a copy of m with key set to value. The original map is left untouched, as it may be part of a
+// published snapshot.
+func with[K comparable, V any](m map[K]V, key K, value V) map[K]V {
+	next := make(map[K]V, len(m)+1)
+	maps.Copy(next, m)
+	next[key] = value
+	return next
+}
+
+// store sets the value of key in the map of r matching the type of the key. The map is replaced rather than modified,
+// see with.
+func store[T constraint](r *registry, key Variable[T], value T) {
+	switch k := any(key).(type) {
+	case Variable[string]:
+		r.regString = with(r.regString, k, any(value).(string))
+	case Variable[int]:
+		r.regInt = with(r.regInt, k, any(value).(int))
+	case Variable[int8]:
+		r.regInt8 = with(r.regInt8, k, any(value).(int8))
+	case Variable[int16]:
+		r.regInt16 = with(r.regInt16, k, any(value).(int16))
+	case Variable[int32]:
+		r.regInt32 = with(r.regInt32, k, any(value).(int32))
+	case Variable[int64]:
+		r.regInt64 = with(r.regInt64, k, any(value).(int64))
+	case Variable[uint]:
+		r.regUint = with(r.regUint, k, any(value).(uint))
+	case Variable[uint8]:
+		r.regUint8 = with(r.regUint8, k, any(value).(uint8))
+	case Variable[uint16]:
+		r.regUint16 = with(r.regUint16, k, any(value).(uint16))
+	case Variable[uint32]:
+		r.regUint32 = with(r.regUint32, k, any(value).(uint32))
+	case Variable[uint64]:
+		r.regUint64 = with(r.regUint64, k, any(value).(uint64))
+	case Variable[uintptr]:
+		r.regUintptr = with(r.regUintptr, k, any(value).(uintptr))
+	case Variable[[]byte]:
+		r.regBytes = with(r.regBytes, k, any(value).([]byte))
+	case Variable[[]rune]:
+		r.regRunes = with(r.regRunes, k, any(value).([]rune))
+	case Variable[float32]:
+		r.regFloat32 = with(r.regFloat32, k, any(value).(float32))
+	case Variable[float64]:
+		r.regFloat64 = with(r.regFloat64, k, any(value).(float64))
+	case Variable[bool]:
+		r.regBool = with(r.regBool, k, any(value).(bool))
+	case Variable[time.Time]:
+		r.regTime = with(r.regTime, k, any(value).(time.Time))
+	case Variable[time.Duration]:
+		r.regDuration = with(r.regDuration, k, any(value).(time.Duration))
+	default:
+		r.regOther = with(r.regOther, any(key), any(value))
+	}
+}
+
+// valueOf returns the value of key from the map of r matching the type of the key, and whether it is registered.
+func valueOf[T constraint](r *registry, key Variable[T]) (T, bool) {
+	var value any
+	var ok bool
+	switch k := any(key).(type) {
+	case Variable[string]:
+		value, ok = r.regString[k]
+	case Variable[int]:
+		value, ok = r.regInt[k]
+	case Variable[int8]:
+		value, ok = r.regInt8[k]
+	case Variable[int16]:
+		value, ok = r.regInt16[k]
+	case Variable[int32]:
+		value, ok = r.regInt32[k]
+	case Variable[int64]:
+		value, ok = r.regInt64[k]
+	case Variable[uint]:
+		value, ok = r.regUint[k]
+	case Variable[uint8]:
+		value, ok = r.regUint8[k]
+	case Variable[uint16]:
+		value, ok = r.regUint16[k]
+	case Variable[uint32]:
+		value, ok = r.regUint32[k]
+	case Variable[uint64]:
+		value, ok = r.regUint64[k]
+	case Variable[uintptr]:
+		value, ok = r.regUintptr[k]
+	case Variable[[]byte]:
+		value, ok = r.regBytes[k]
+	case Variable[[]rune]:
+		value, ok = r.regRunes[k]
+	case Variable[float32]:
+		value, ok = r.regFloat32[k]
+	case Variable[float64]:
+		value, ok = r.regFloat64[k]
+	case Variable[bool]:
+		value, ok = r.regBool[k]
+	case Variable[time.Time]:
+		value, ok = r.regTime[k]
+	case Variable[time.Duration]:
+		value, ok = r.regDuration[k]
+	default:
+		value, ok = r.regOther[k]
+	}
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return value.(T), true
+}
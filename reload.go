@@ -0,0 +1,348 @@
+package configura
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// Triggers reported by ReloadEvent for reloads that weren't started by a file change.
+const (
+	TriggerCall     = "call"
+	TriggerSignal   = "signal"
+	TriggerRollback = "rollback"
+)
+
+// DefaultRollbackHistory is the number of configurations replaced by reloads that a configuration created with
+// NewConfigImpl keeps for Rollback, unless changed with SetRollbackHistory.
+const DefaultRollbackHistory = 10
+
+// ReloadEvent describes the outcome of a reload of the configuration, see ConfigImpl.OnReload.
+type ReloadEvent struct {
+	// Trigger is what started the reload: TriggerCall, TriggerSignal, TriggerRollback, or the path of a file that
+	// changed.
+	Trigger string
+	// Changed holds the names of the configuration variables whose value changed, sorted by name.
+	Changed []string
+	// Err is the reason the reload failed, in which case the configuration was left untouched. It is a *ReloadError.
+	Err error
+}
+
+// Stages of a reload reported by ReloadError.
+const (
+	StageRead     = "reading sources"
+	StageLoad     = "loading variables"
+	StageValidate = "validating"
+)
+
+// ReloadError is returned by Reload, and reported by ReloadEvent, when a reload is rejected. The configuration the
+// process is running with is left as it was before the reload.
+type ReloadError struct {
+	Trigger string // What started the reload, see ReloadEvent.
+	Stage   string // Stage the reload failed at: StageRead, StageLoad or StageValidate.
+	Err     error  // Reasons the reload was rejected, e.g. a *SyntaxError, *ParseError or MissingVariableError.
+}
+
+// Error implements the error interface for ReloadError.
+func (e *ReloadError) Error() string {
+	return fmt.Sprintf("configuration reload triggered by %s rejected while %s, keeping the previous configuration: %v", e.Trigger, e.Stage, e.Err)
+}
+
+// Unwrap returns the reasons the reload was rejected.
+func (e *ReloadError) Unwrap() error {
+	return e.Err
+}
+
+var _ error = (*ReloadError)(nil)
+
+// ErrNoSnapshot is returned by Rollback when fewer configurations than requested were replaced by reloads.
+var ErrNoSnapshot = errors.New("no configuration snapshot to roll back to")
+
+// Reload reads every file-backed source of the configuration again, and loads every variable previously loaded with
+// LoadEnvironment again from the sources, with the same fallback. Values set with WriteConfiguration are kept.
+//
+// The reload is all or nothing: if a source can't be read, a value can't be parsed, or a validator registered with
+// AddValidator rejects the new configuration, the configuration is left untouched and a *ReloadError is returned.
+// Otherwise the new values are published at once, so readers see either the previous or the new configuration,
+// never a mix, and the previous configuration is kept for Rollback. Subscribers of variables that changed are
+// notified, see Subscribe, and so are the listeners registered with OnReload, whether the reload succeeded or not.
+func (c *ConfigImpl) Reload() error {
+	return c.reload(TriggerCall)
+}
+
+func (c *ConfigImpl) reload(trigger string) error {
+	var prev, next *registry
+	return c.updateThen(func(r *registry) error {
+		prev, next = c.load(), r
+
+		var errs []error
+		sources := make([]Source, len(r.sources))
+		for i, source := range r.sources {
+			if reloadable, ok := source.(reloader); ok {
+				fresh, err := reloadable.reload()
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				source = fresh
+			}
+			sources[i] = source
+		}
+		if len(errs) > 0 {
+			return &ReloadError{Trigger: trigger, Stage: StageRead, Err: errors.Join(errs...)}
+		}
+		r.sources = sources
+
+		for _, b := range sortedBindings(r.bindings) {
+			if err := b.load(r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return &ReloadError{Trigger: trigger, Stage: StageLoad, Err: errors.Join(errs...)}
+		}
+
+		if err := c.validate(r); err != nil {
+			return &ReloadError{Trigger: trigger, Stage: StageValidate, Err: err}
+		}
+		c.remember(prev)
+		return nil
+	}, func(err error) {
+		event := ReloadEvent{Trigger: trigger, Err: err}
+		if err == nil {
+			event.Changed = changedVariables(prev, next)
+		}
+		c.emit(event)
+	})
+}
+
+// Rollback reverts the configuration to the one in place before the last n successful reloads, so Rollback(1) undoes
+// the last reload. Everything is reverted, including the sources and values written since. The configurations
+// rolled back over are discarded, and ErrNoSnapshot is returned if fewer than n are kept, see SetRollbackHistory.
+//
+// Subscribers and the listeners registered with OnReload are notified as for a reload, with TriggerRollback.
+func (c *ConfigImpl) Rollback(n int) error {
+	var prev, next *registry
+	return c.updateThen(func(r *registry) error {
+		if n < 1 || n > len(c.history) {
+			return fmt.Errorf("%w: %d requested, %d kept", ErrNoSnapshot, n, len(c.history))
+		}
+		prev = c.load()
+		next = c.history[len(c.history)-n]
+		*r = *next
+		c.history = slices.Clone(c.history[:len(c.history)-n])
+		return nil
+	}, func(err error) {
+		if err == nil {
+			c.emit(ReloadEvent{Trigger: TriggerRollback, Changed: changedVariables(prev, next)})
+		}
+	})
+}
+
+// SetRollbackHistory sets the number of configurations replaced by reloads that are kept for Rollback. The oldest are
+// discarded first; zero disables Rollback.
+func (c *ConfigImpl) SetRollbackHistory(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.historyLimit = max(n, 0)
+	c.trimHistory()
+}
+
+// remember keeps a configuration replaced by a reload for Rollback. It must be called with c.mu held.
+func (c *ConfigImpl) remember(r *registry) {
+	c.history = append(slices.Clip(c.history), r)
+	c.trimHistory()
+}
+
+// trimHistory discards the oldest configurations kept for Rollback beyond the limit. It must be called with c.mu held.
+func (c *ConfigImpl) trimHistory() {
+	if len(c.history) > c.historyLimit {
+		c.history = slices.Clone(c.history[len(c.history)-c.historyLimit:])
+	}
+}
+
+// AddValidator registers fn to validate the configuration produced by every reload before it is published, until
+// the returned function is called. If fn returns an error, the reload is rejected and the previous configuration is
+// kept. Checking required variables is a common validation:
+//
+//	cfg.AddValidator(func(c configura.Config) error {
+//		return c.ConfigurationKeysRegistered(DATABASE_URL, PORT)
+//	})
+//
+// fn is called with the configuration lock held, so it must only read the Config it is given.
+func (c *ConfigImpl) AddValidator(fn func(Config) error) (remove func()) {
+	return register(&c.listenMu, &c.validators, fn)
+}
+
+// validate runs the validators on a candidate configuration, joining their errors.
+func (c *ConfigImpl) validate(r *registry) error {
+	candidate := &ConfigImpl{}
+	candidate.snapshot.Store(r)
+
+	var errs []error
+	for _, fn := range registered(&c.listenMu, &c.validators) {
+		errs = append(errs, (*fn)(candidate))
+	}
+	return errors.Join(errs...)
+}
+
+// changedVariables returns the sorted names of the variables loaded with LoadEnvironment whose value differs between
+// two configurations.
+func changedVariables(prev, next *registry) []string {
+	bindings := maps.Clone(next.bindings)
+	maps.Copy(bindings, prev.bindings)
+
+	var changed []string
+	for _, b := range sortedBindings(bindings) {
+		if b.changed(prev, next) {
+			changed = append(changed, b.name())
+		}
+	}
+	return changed
+}
+
+// ReloadOnSignal reloads the configuration whenever the process receives one of the signals, SIGHUP if none are
+// provided, until the returned function is called. Outcomes of the reloads are reported to the listeners registered
+// with OnReload.
+func (c *ConfigImpl) ReloadOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = c.reload(TriggerSignal)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// OnReload registers fn to be called with the outcome of every reload, until the returned function is called. It is
+// called after the new configuration is published, in the order of the reloads, on the same goroutine as the
+// subscribers to changes, see Subscribe.
+func (c *ConfigImpl) OnReload(fn func(ReloadEvent)) (cancel func()) {
+	return register(&c.listenMu, &c.reloadListeners, fn)
+}
+
+func (c *ConfigImpl) emit(event ReloadEvent) {
+	for _, fn := range registered(&c.listenMu, &c.reloadListeners) {
+		(*fn)(event)
+	}
+}
+
+// binding records how a configuration variable was loaded with LoadEnvironment, so it can be loaded again when the
+// configuration is reloaded.
+type binding interface {
+	// load loads the variable from the sources of r into r, registering the fallback value if that fails.
+	load(r *registry) error
+	// changed reports whether the value of the variable differs between two registries.
+	changed(prev, next *registry) bool
+	// name returns the name of the variable.
+	name() string
+}
+
+// variableBinding is the binding of a configuration variable of type T.
+type variableBinding[T constraint] struct {
+	key      Variable[T]
+	fallback T
+	opts     loadOptions
+}
+
+func (b variableBinding[T]) load(r *registry) error {
+	value, origin, secret, err := lookup(r.sources, b.key, b.fallback, b.opts)
+	store(r, b.key, value)
+	r.origins = with(r.origins, any(b.key), origin)
+	r.secrets = with(r.secrets, any(b.key), secret)
+	return err
+}
+
+func (b variableBinding[T]) changed(prev, next *registry) bool {
+	old, hadOld := valueOf(prev, b.key)
+	value, hasValue := valueOf(next, b.key)
+	return hadOld != hasValue || !equal(old, value)
+}
+
+func (b variableBinding[T]) name() string {
+	return string(b.key)
+}
+
+// optionsOf returns the options the variable key was loaded with, or the default options if it wasn't loaded with
+// LoadEnvironment.
+func optionsOf[T constraint](r *registry, key Variable[T]) loadOptions {
+	b, _ := r.bindings[any(key)].(variableBinding[T])
+	return b.opts
+}
+
+// sortedBindings returns the bindings sorted by the name of their variable, so reloads behave deterministically.
+func sortedBindings(bindings map[any]binding) []binding {
+	sorted := make([]binding, 0, len(bindings))
+	for _, b := range bindings {
+		sorted = append(sorted, b)
+	}
+	slices.SortFunc(sorted, func(a, b binding) int {
+		return cmp.Or(cmp.Compare(a.name(), b.name()), cmp.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)))
+	})
+	return sorted
+}
+
+// reloader is implemented by sources whose values can change after they are created, e.g. because they are read
+// from a file.
+type reloader interface {
+	// reload returns a new source holding the current values.
+	reload() (Source, error)
+}
+
+// fileSource is a Source read from a file or directory, which is read again when the configuration is reloaded.
+type fileSource struct {
+	Source
+	path string
+	read func() (Source, error)
+}
+
+func (s fileSource) reload() (Source, error) {
+	return s.read()
+}
+
+// keys forwards to the underlying source if it can enumerate its keys.
+func (s fileSource) keys() []string {
+	if lister, ok := s.Source.(keyLister); ok {
+		return lister.keys()
+	}
+	return nil
+}
+
+// secret forwards to the underlying source, so wrapping a source doesn't reveal its secrets.
+func (s fileSource) secret(key string) bool {
+	return isSecret(s.Source, key)
+}
+
+// lookupDocument forwards to the underlying source if it serves a structured document, so wrapping a source doesn't
+// change how its values are parsed.
+func (s fileSource) lookupDocument(key string) (documentValue, bool) {
+	if document, ok := s.Source.(documentLookuper); ok {
+		return document.lookupDocument(key)
+	}
+	raw, ok := s.Source.Lookup(key)
+	return documentValue{raw: raw, kind: kindString}, ok
+}
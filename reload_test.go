@@ -0,0 +1,330 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ReloadSuite tests reloading the configuration from its sources at runtime.
+type ReloadSuite struct {
+	suite.Suite
+	mu     sync.Mutex
+	values map[string]string
+	config *ConfigImpl
+}
+
+func (s *ReloadSuite) SetupTest() {
+	s.values = map[string]string{"PORT": "8080", "NAME": "service"}
+	s.config = NewConfigImpl()
+	s.config.SetSources(SourceFunc("test", func(key string) (string, bool) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		v, ok := s.values[key]
+		return v, ok
+	}))
+}
+
+func (s *ReloadSuite) write(path, content string) {
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+}
+
+func (s *ReloadSuite) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+func (s *ReloadSuite) TestReload() {
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{"NAME": "written"}))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("HOST"), "localhost"))
+
+	s.set("PORT", "9090")
+	s.set("HOST", "example.com")
+	s.set("NAME", "ignored")
+	s.Require().NoError(s.config.Reload())
+
+	s.Equal(9090, s.config.Int("PORT"))
+	s.Equal("example.com", s.config.String("HOST"))
+	s.Equal("test", s.config.Origin(Variable[string]("HOST")))
+	s.Equal("written", s.config.String("NAME"), "values set with WriteConfiguration are kept")
+}
+
+func (s *ReloadSuite) TestReloadAfterWrite() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("NAME"), ""))
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[int]]int{"TIMEOUT": 5}))
+	s.Require().ErrorIs(s.config.ConfigurationKeysRegistered(Variable[int]("PORT")), ErrMissingVariable)
+
+	s.Require().NoError(s.config.Reload())
+	s.ErrorIs(s.config.ConfigurationKeysRegistered(Variable[int]("PORT")), ErrMissingVariable,
+		"a variable removed by a write isn't reloaded")
+	s.Empty(s.config.Origin(Variable[int]("PORT")))
+	s.Equal(5, s.config.Int("TIMEOUT"))
+	s.Equal("service", s.config.String("NAME"), "variables of other types are still reloaded")
+}
+
+func (s *ReloadSuite) TestReloadKeepsPreviousOnError() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("NAME"), ""))
+	before := s.config.Snapshot()
+
+	s.set("PORT", "not-a-port")
+	s.set("NAME", "renamed")
+	err := s.config.Reload()
+
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("PORT", parseErr.Key)
+	s.ErrorIs(err, strconv.ErrSyntax)
+	s.Equal(8080, s.config.Int("PORT"))
+	s.Equal("service", s.config.String("NAME"), "no value of a failed reload is published")
+	s.Equal(before, s.config.Snapshot())
+}
+
+func (s *ReloadSuite) TestReloadFile() {
+	path := filepath.Join(s.T().TempDir(), "config.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`{"timeout": 5}`), 0o600))
+	s.Require().NoError(LoadJSON(s.config, path))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("TIMEOUT"), 0))
+
+	s.Require().NoError(os.WriteFile(path, []byte(`{"timeout": 10}`), 0o600))
+	s.Require().NoError(s.config.Reload())
+	s.Equal(10, s.config.Int("TIMEOUT"))
+
+	s.Require().NoError(os.WriteFile(path, []byte(`{"timeout": `), 0o600))
+	var syntaxErr *SyntaxError
+	s.ErrorAs(s.config.Reload(), &syntaxErr)
+	s.Equal(10, s.config.Int("TIMEOUT"))
+}
+
+func (s *ReloadSuite) TestEvents() {
+	var events []ReloadEvent
+	cancel := s.config.OnReload(func(event ReloadEvent) {
+		events = append(events, event)
+	})
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("NAME"), ""))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("HOST"), ""))
+
+	s.set("PORT", "9090")
+	s.set("HOST", "example.com")
+	s.Require().NoError(s.config.Reload())
+	s.set("PORT", "invalid")
+	s.Require().Error(s.config.Reload())
+	cancel()
+	s.Require().Error(s.config.Reload())
+
+	s.Require().Len(events, 2)
+	s.Equal(ReloadEvent{Trigger: TriggerCall, Changed: []string{"HOST", "PORT"}}, events[0])
+	s.Equal(TriggerCall, events[1].Trigger)
+	s.Empty(events[1].Changed)
+	s.ErrorIs(events[1].Err, strconv.ErrSyntax)
+}
+
+func (s *ReloadSuite) TestConcurrentEventsInOrder() {
+	var counter int
+	s.config.SetSources(SourceFunc("counter", func(key string) (string, bool) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		counter++
+		return strconv.Itoa(counter), true
+	}))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+
+	var mu sync.Mutex
+	var log []string
+	defer Subscribe(s.config, Variable[int]("PORT"), func(Change[int]) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, "change")
+	})()
+	defer s.config.OnReload(func(ReloadEvent) {
+		time.Sleep(100 * time.Microsecond)
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, "reload")
+	})()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.NoError(s.config.Reload())
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Require().Len(log, 40)
+	for i := 0; i < len(log); i += 2 {
+		s.Equal([]string{"change", "reload"}, log[i:i+2], "every reload is reported after its changes")
+	}
+}
+
+func (s *ReloadSuite) TestConcurrentReads() {
+	s.set("NAME", "service-8080")
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("NAME"), ""))
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			snapshot := s.config.Snapshot()
+			port, name := snapshot.Int("PORT"), snapshot.String("NAME")
+			if strconv.Itoa(port) != name[len("service-"):] {
+				errs <- errors.New("reload observed half applied: " + name + " with port " + strconv.Itoa(port))
+				return
+			}
+		}
+	}()
+
+	for port := 8081; port < 8181; port++ {
+		s.set("PORT", strconv.Itoa(port))
+		s.set("NAME", "service-"+strconv.Itoa(port))
+		s.Require().NoError(s.config.Reload())
+	}
+	close(done)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		s.Fail(err.Error())
+	default:
+	}
+}
+
+func (s *ReloadSuite) TestValidation() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	remove := s.config.AddValidator(func(c Config) error {
+		if port := c.Int("PORT"); port < 1024 {
+			return fmt.Errorf("port %d is privileged", port)
+		}
+		return nil
+	})
+	var events []ReloadEvent
+	defer s.config.OnReload(func(event ReloadEvent) { events = append(events, event) })()
+
+	s.set("PORT", "443")
+	err := s.config.Reload()
+	s.EqualError(err, "configuration reload triggered by call rejected while validating, keeping the previous configuration: port 443 is privileged")
+	var reloadErr *ReloadError
+	s.Require().ErrorAs(err, &reloadErr)
+	s.Equal(StageValidate, reloadErr.Stage)
+	s.Equal(8080, s.config.Int("PORT"))
+	s.Require().Len(events, 1)
+	s.Equal(err, events[0].Err)
+
+	remove()
+	s.Require().NoError(s.config.Reload())
+	s.Equal(443, s.config.Int("PORT"))
+}
+
+func (s *ReloadSuite) TestValidateRequiredKeys() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.config.AddValidator(func(c Config) error {
+		return c.ConfigurationKeysRegistered(Variable[int]("PORT"), Variable[string]("API_KEY"))
+	})
+
+	s.set("PORT", "9090")
+	err := s.config.Reload()
+	s.ErrorIs(err, ErrMissingVariable)
+	var missing MissingVariableError
+	s.Require().ErrorAs(err, &missing)
+	s.Equal([]string{"API_KEY"}, missing.Keys)
+	s.Equal(8080, s.config.Int("PORT"))
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("API_KEY"), ""))
+	s.Require().NoError(s.config.Reload())
+	s.Equal(9090, s.config.Int("PORT"))
+}
+
+func (s *ReloadSuite) TestStages() {
+	path := filepath.Join(s.T().TempDir(), "config.json")
+	s.write(path, `{"timeout": 5}`)
+	s.Require().NoError(LoadJSON(s.config, path))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("TIMEOUT"), 0))
+
+	var reloadErr *ReloadError
+	s.write(path, `{`)
+	s.Require().ErrorAs(s.config.Reload(), &reloadErr)
+	s.Equal(StageRead, reloadErr.Stage)
+	s.Equal(TriggerCall, reloadErr.Trigger)
+
+	s.write(path, `{"timeout": "soon"}`)
+	s.Require().ErrorAs(s.config.Reload(), &reloadErr)
+	s.Equal(StageLoad, reloadErr.Stage)
+	s.Equal(5, s.config.Int("TIMEOUT"))
+}
+
+func (s *ReloadSuite) TestRollback() {
+	key := Variable[int]("PORT")
+	s.Require().NoError(LoadEnvironment(s.config, key, 80))
+	var changes []Change[int]
+	defer Subscribe(s.config, key, func(change Change[int]) { changes = append(changes, change) })()
+	var events []ReloadEvent
+	defer s.config.OnReload(func(event ReloadEvent) { events = append(events, event) })()
+
+	for _, port := range []string{"8081", "8082", "8083"} {
+		s.set("PORT", port)
+		s.Require().NoError(s.config.Reload())
+	}
+
+	s.Require().NoError(s.config.Rollback(1))
+	s.Equal(8082, s.config.Int(key))
+	s.Equal(Change[int]{Key: key, Old: 8083, New: 8082}, changes[len(changes)-1])
+	s.Equal(ReloadEvent{Trigger: TriggerRollback, Changed: []string{"PORT"}}, events[len(events)-1])
+
+	s.Require().NoError(s.config.Rollback(2))
+	s.Equal(8080, s.config.Int(key))
+
+	err := s.config.Rollback(1)
+	s.ErrorIs(err, ErrNoSnapshot)
+	s.EqualError(err, "no configuration snapshot to roll back to: 1 requested, 0 kept")
+	s.Equal(8080, s.config.Int(key))
+}
+
+func (s *ReloadSuite) TestRollbackHistory() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.config.SetRollbackHistory(2)
+	for _, port := range []string{"8081", "8082", "8083"} {
+		s.set("PORT", port)
+		s.Require().NoError(s.config.Reload())
+	}
+
+	s.ErrorIs(s.config.Rollback(3), ErrNoSnapshot)
+	s.Require().NoError(s.config.Rollback(2))
+	s.Equal(8081, s.config.Int("PORT"))
+
+	s.set("PORT", "invalid")
+	s.Require().Error(s.config.Reload())
+	s.ErrorIs(s.config.Rollback(1), ErrNoSnapshot, "rejected reloads are not kept")
+
+	s.config.SetRollbackHistory(0)
+	s.set("PORT", "9000")
+	s.Require().NoError(s.config.Reload())
+	s.ErrorIs(s.config.Rollback(1), ErrNoSnapshot)
+}
+
+func TestReloadSuite(t *testing.T) {
+	suite.Run(t, new(ReloadSuite))
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package configura
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func (s *ReloadSuite) TestReloadOnSignal() {
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	events := make(chan ReloadEvent, 1)
+	defer s.config.OnReload(func(event ReloadEvent) { events <- event })()
+
+	stop := s.config.ReloadOnSignal()
+	defer stop()
+
+	s.set("PORT", "9090")
+	s.Require().NoError(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case event := <-events:
+		s.Equal(TriggerSignal, event.Trigger)
+		s.NoError(event.Err)
+	case <-time.After(5 * time.Second):
+		s.FailNow("no reload after SIGHUP")
+	}
+	s.Equal(9090, s.config.Int("PORT"))
+}
@@ -0,0 +1,107 @@
+package configura
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SecretFileSuffix is appended to the key of a configuration variable to name the variable holding the path of the
+// file its value is read from, see SecretFiles.
+const SecretFileSuffix = "_FILE"
+
+// SecretFiles returns a Source serving the values of source, that reads the value of a key from a file when source
+// doesn't hold the key itself, but holds the key with SecretFileSuffix appended. This is the convention used by
+// Docker and Kubernetes to pass secrets: with DATABASE_PASSWORD_FILE=/run/secrets/db_pw, the value of
+// DATABASE_PASSWORD is the content of /run/secrets/db_pw. A single trailing newline is removed from the content if
+// trimNewline is set.
+//
+// The path of the file is reported as the origin of the value, see ConfigImpl.Origin, and values read from files are
+// treated as secrets: they are not shown as the default of command-line flags. If the file can't be read,
+// LoadEnvironment registers the fallback value and returns an error naming the variable and the file.
+func SecretFiles(source Source, trimNewline bool) Source {
+	return secretFileSource{source: source, trimNewline: trimNewline}
+}
+
+type secretFileSource struct {
+	source      Source
+	trimNewline bool
+}
+
+func (s secretFileSource) Name() string {
+	return s.source.Name()
+}
+
+func (s secretFileSource) Lookup(key string) (string, bool) {
+	raw, _, ok, err := s.lookupOrigin(key)
+	return raw, ok && err == nil
+}
+
+// reload reloads the wrapped source. Secret files themselves are read on every lookup, so they are always current.
+func (s secretFileSource) reload() (Source, error) {
+	reloadable, ok := s.source.(reloader)
+	if !ok {
+		return s, nil
+	}
+	source, err := reloadable.reload()
+	if err != nil {
+		return nil, err
+	}
+	return secretFileSource{source: source, trimNewline: s.trimNewline}, nil
+}
+
+// keys returns the keys of the wrapped source, with the keys naming a file listed as the key whose value is read
+// from it.
+func (s secretFileSource) keys() []string {
+	lister, ok := s.source.(keyLister)
+	if !ok {
+		return nil
+	}
+	keys := lister.keys()
+	for i, key := range keys {
+		if name, ok := strings.CutSuffix(key, SecretFileSuffix); ok && name != "" {
+			keys[i] = name
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// secret reports whether the value of key is read from a file, or is a secret of the wrapped source.
+func (s secretFileSource) secret(key string) bool {
+	if _, ok := s.source.Lookup(key); ok {
+		return isSecret(s.source, key)
+	}
+	_, ok := s.source.Lookup(key + SecretFileSuffix)
+	return ok
+}
+
+func (s secretFileSource) lookupOrigin(key string) (string, string, bool, error) {
+	if raw, origin, ok, err := lookupRaw(s.source, key); ok {
+		return raw, origin, ok, err
+	}
+
+	path, _, ok, err := lookupRaw(s.source, key+SecretFileSuffix)
+	if !ok || err != nil {
+		return "", "", ok, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", path, true, fmt.Errorf("configuration variable %s: reading %s%s: %w", key, key, SecretFileSuffix, err)
+	}
+
+	raw := string(content)
+	if s.trimNewline {
+		raw = trimTrailingNewline(raw)
+	}
+	return raw, path, true, nil
+}
+
+// trimTrailingNewline removes a single trailing newline, written as either \n or \r\n, from the content of a file.
+func trimTrailingNewline(content string) string {
+	if !strings.HasSuffix(content, "\n") {
+		return content
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(content, "\n"), "\r")
+}
@@ -0,0 +1,123 @@
+package configura
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SecretFileSuite tests reading configuration variables from files named by <KEY>_FILE.
+type SecretFileSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *SecretFileSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// write creates a file named name holding content in the temporary directory, and returns its path.
+func (s *SecretFileSuite) write(name, content string) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (s *SecretFileSuite) config(trimNewline bool, values map[string]string) *ConfigImpl {
+	cfg := NewConfigImpl()
+	cfg.SetSources(SecretFiles(MapSource("environment", values), trimNewline))
+	return cfg
+}
+
+func (s *SecretFileSuite) TestReadsFile() {
+	path := s.write("db_pw", "s3cret\n")
+	key := Variable[string]("DATABASE_PASSWORD")
+
+	s.Run("TrimNewline", func() {
+		cfg := s.config(true, map[string]string{"DATABASE_PASSWORD_FILE": path})
+		s.Require().NoError(LoadEnvironment(cfg, key, ""))
+		s.Equal("s3cret", cfg.String(key))
+		s.Equal(path, cfg.Origin(key))
+	})
+	s.Run("KeepNewline", func() {
+		cfg := s.config(false, map[string]string{"DATABASE_PASSWORD_FILE": path})
+		s.Require().NoError(LoadEnvironment(cfg, key, ""))
+		s.Equal("s3cret\n", cfg.String(key))
+	})
+	s.Run("CRLF", func() {
+		cfg := s.config(true, map[string]string{"DATABASE_PASSWORD_FILE": s.write("crlf", "s3cret\r\n")})
+		s.Require().NoError(LoadEnvironment(cfg, key, ""))
+		s.Equal("s3cret", cfg.String(key))
+	})
+	s.Run("OnlyOneNewline", func() {
+		cfg := s.config(true, map[string]string{"DATABASE_PASSWORD_FILE": s.write("multi", "s3cret\n\n")})
+		s.Require().NoError(LoadEnvironment(cfg, key, ""))
+		s.Equal("s3cret\n", cfg.String(key))
+	})
+}
+
+func (s *SecretFileSuite) TestVariableTakesPrecedence() {
+	cfg := s.config(true, map[string]string{
+		"DATABASE_PASSWORD":      "from-env",
+		"DATABASE_PASSWORD_FILE": s.write("db_pw", "from-file"),
+	})
+	key := Variable[string]("DATABASE_PASSWORD")
+
+	s.Require().NoError(LoadEnvironment(cfg, key, ""))
+	s.Equal("from-env", cfg.String(key))
+	s.Equal("environment", cfg.Origin(key))
+}
+
+func (s *SecretFileSuite) TestTypedValue() {
+	path := s.write("port", "300\n")
+	cfg := s.config(true, map[string]string{"PORT_FILE": path})
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("PORT"), 0))
+	s.Equal(300, cfg.Int("PORT"))
+
+	err := LoadEnvironment(cfg, Variable[uint8]("PORT"), 80)
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal(path, parseErr.Source)
+	s.ErrorIs(err, strconv.ErrRange)
+	s.Equal(uint8(80), cfg.Uint8("PORT"))
+	s.True(parseErr.Redacted)
+	s.Empty(parseErr.Value)
+	s.NotContains(err.Error(), `"300"`, "values read from files are secrets")
+}
+
+func (s *SecretFileSuite) TestMissingFile() {
+	path := filepath.Join(s.dir, "missing")
+	cfg := s.config(true, map[string]string{"DATABASE_PASSWORD_FILE": path})
+	key := Variable[string]("DATABASE_PASSWORD")
+
+	err := LoadEnvironment(cfg, key, "fallback")
+	s.ErrorIs(err, os.ErrNotExist)
+	s.EqualError(err, "configuration variable DATABASE_PASSWORD: reading DATABASE_PASSWORD_FILE: open "+path+": no such file or directory")
+	s.Equal("fallback", cfg.String(key))
+	s.Equal(OriginFallback, cfg.Origin(key))
+}
+
+func (s *SecretFileSuite) TestUnreadableFile() {
+	cfg := s.config(true, map[string]string{"DATABASE_PASSWORD_FILE": s.dir})
+
+	err := LoadEnvironment(cfg, Variable[string]("DATABASE_PASSWORD"), "")
+	s.ErrorContains(err, "reading DATABASE_PASSWORD_FILE")
+}
+
+func (s *SecretFileSuite) TestUnset() {
+	cfg := s.config(true, map[string]string{})
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[string]("DATABASE_PASSWORD"), "fallback"))
+	s.Equal("fallback", cfg.String(Variable[string]("DATABASE_PASSWORD")))
+
+	_, ok := SecretFiles(MapSource("environment", nil), true).Lookup("DATABASE_PASSWORD")
+	s.False(ok)
+}
+
+func TestSecretFileSuite(t *testing.T) {
+	suite.Run(t, new(SecretFileSuite))
+}
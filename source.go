@@ -0,0 +1,232 @@
+package configura
+
+import (
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// OriginFallback is reported by ConfigImpl.Origin for configuration variables that none of the sources provided a
+// valid value for, so the fallback value was registered instead.
+const OriginFallback = "fallback"
+
+// Source provides raw configuration values by key. LoadEnvironment draws the values it parses into typed
+// configuration variables from the sources of a ConfigImpl, see ConfigImpl.SetSources. Implementations must be safe
+// for concurrent use.
+type Source interface {
+	// Name identifies the source, it is reported by ConfigImpl.Origin and included in errors.
+	Name() string
+	// Lookup returns the raw value stored under the key, and whether the key is present in the source.
+	Lookup(key string) (string, bool)
+}
+
+// Environment is the Source reading values from environment variables. It is the only source of a ConfigImpl
+// unless ConfigImpl.SetSources is used.
+var Environment Source = environmentSource{}
+
+type environmentSource struct{}
+
+func (environmentSource) Name() string {
+	return "environment"
+}
+
+func (environmentSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (environmentSource) keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if key, _, ok := strings.Cut(kv, "="); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// MapSource returns a Source serving the provided values. The map is copied, so later changes to it are not
+// reflected by the source.
+func MapSource(name string, values map[string]string) Source {
+	return mapSource{name: name, values: maps.Clone(values)}
+}
+
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+func (s mapSource) Name() string {
+	return s.name
+}
+
+func (s mapSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s mapSource) keys() []string {
+	return slices.Collect(maps.Keys(s.values))
+}
+
+// SourceFunc returns a Source named name, that looks up values with the provided function.
+func SourceFunc(name string, lookup func(key string) (string, bool)) Source {
+	return funcSource{name: name, lookup: lookup}
+}
+
+type funcSource struct {
+	name   string
+	lookup func(key string) (string, bool)
+}
+
+func (s funcSource) Name() string {
+	return s.name
+}
+
+func (s funcSource) Lookup(key string) (string, bool) {
+	return s.lookup(key)
+}
+
+// lookup resolves the value of key from the sources, in order of precedence. The first source holding the key
+// decides the value; if its raw value can't be read or parsed, the fallback is used and the error, usually a
+// *ParseError, is returned. Where the value came from, or OriginFallback, is returned as the origin, and the boolean
+// reports whether the value is a secret, see isSecret.
+func lookup[T constraint](sources []Source, key Variable[T], fallback T, opts loadOptions) (T, string, bool, error) {
+	for _, source := range sources {
+		value, origin, ok, err := lookupSource(source, key, opts)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return fallback, OriginFallback, false, err
+		}
+		return value, origin, opts.sensitive || isSecret(source, string(key)), nil
+	}
+	return fallback, OriginFallback, false, nil
+}
+
+// secretSource is implemented by sources holding secrets, like secret files and systemd credentials, whose values
+// must not be shown, e.g. as the default of a command-line flag.
+type secretSource interface {
+	secret(key string) bool
+}
+
+// isSecret reports whether the value of key held by source is a secret.
+func isSecret(source Source, key string) bool {
+	s, ok := source.(secretSource)
+	return ok && s.secret(key)
+}
+
+// lookupSource retrieves the raw value of key from a single source and parses it. The boolean reports whether the
+// key is present in the source. The origin is the name of the source, unless the source reports a more precise
+// one, e.g. the file a secret was read from. Secrets are left out of the returned error, see redact.
+func lookupSource[T constraint](source Source, key Variable[T], opts loadOptions) (T, string, bool, error) {
+	var value T
+	origin, ok, err := lookupValue(source, string(key), reflect.ValueOf(&value).Elem(), opts)
+	if err != nil {
+		if opts.redacted() || isSecret(source, string(key)) {
+			redact(err)
+		}
+		var zero T
+		return zero, origin, ok, err
+	}
+	return value, origin, ok, nil
+}
+
+// lookupValue retrieves the raw value of key from a single source and parses it into v, see lookupSource. Lists
+// missing from the source, or held as an array by a document, are looked up one key per element, see lookupIndexed.
+// Likewise, maps missing from the source are collected from the keys sharing their prefix, see lookupPrefixed, and
+// maps held as an object by a document from its members.
+func lookupValue(source Source, key string, v reflect.Value, opts loadOptions) (string, bool, error) {
+	t := v.Type()
+	if document, ok := source.(documentLookuper); ok {
+		dv, ok := document.lookupDocument(key)
+		switch {
+		case isList(t) && (!ok || dv.kind == kindArray):
+			return lookupIndexed(source, key, v, dv.elements, ok, opts)
+		case isMap(t) && !ok:
+			return lookupPrefixed(source, key, v, opts)
+		case isMap(t) && dv.kind == kindObject:
+			return lookupEntries(source, v, dv.fields, opts)
+		case !ok:
+			return "", false, nil
+		}
+		err := checkKind(t, dv.kind)
+		if err == nil {
+			err = parseValue(v, dv.raw, opts)
+		}
+		if err != nil {
+			return source.Name(), true, &ParseError{
+				Key: key, Value: dv.raw, Type: t.String(), Err: err,
+				Source: source.Name(), Line: dv.line, Column: dv.column,
+			}
+		}
+		return source.Name(), true, nil
+	}
+
+	raw, origin, ok, err := lookupRaw(source, key)
+	if !ok && err == nil && isList(t) {
+		return lookupIndexed(source, key, v, nil, false, opts)
+	}
+	if !ok && err == nil && isMap(t) {
+		return lookupPrefixed(source, key, v, opts)
+	}
+	if !ok || err != nil {
+		return origin, ok, err
+	}
+	if err := parseValue(v, raw, opts); err != nil {
+		return origin, true, &ParseError{Key: key, Value: raw, Type: t.String(), Err: err, Source: origin}
+	}
+	return origin, true, nil
+}
+
+// originLookuper is implemented by sources that know where each of their values comes from more precisely than
+// their name, and that can fail to read a value they hold, e.g. because it is stored in a file.
+type originLookuper interface {
+	lookupOrigin(key string) (raw, origin string, ok bool, err error)
+}
+
+// lookupRaw retrieves the raw value of key from a single source, along with its origin.
+func lookupRaw(source Source, key string) (raw, origin string, ok bool, err error) {
+	if s, ok := source.(originLookuper); ok {
+		return s.lookupOrigin(key)
+	}
+	raw, ok = source.Lookup(key)
+	return raw, source.Name(), ok, nil
+}
+
+// SetSources replaces the sources LoadEnvironment draws values from. Sources are consulted in the order provided, the
+// first source holding a key supplies its value, so sources with the highest precedence go first. Calling SetSources
+// without any source makes LoadEnvironment register fallback values only. Values already loaded are left untouched.
+func (c *ConfigImpl) SetSources(sources ...Source) {
+	_ = c.update(func(r *registry) error {
+		r.sources = append([]Source(nil), sources...)
+		return nil
+	})
+}
+
+// AddSource adds a source with a lower precedence than all sources already set, see SetSources.
+func (c *ConfigImpl) AddSource(source Source) {
+	_ = c.update(func(r *registry) error {
+		r.sources = append(append([]Source(nil), r.sources...), source)
+		return nil
+	})
+}
+
+// Sources returns the sources LoadEnvironment draws values from, in order of precedence.
+func (c *ConfigImpl) Sources() []Source {
+	return append([]Source(nil), c.load().sources...)
+}
+
+// Origin returns the name of the source that supplied the value of a configuration variable loaded through
+// LoadEnvironment, or OriginFallback if its fallback value was used. An empty string is returned for variables that
+// aren't registered, or that were set with WriteConfiguration.
+func (c *ConfigImpl) Origin(key any) string {
+	r := c.load()
+	if _, ok := r.checkKey(key); !ok {
+		return ""
+	}
+	return r.origins[key]
+}
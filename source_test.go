@@ -0,0 +1,138 @@
+package configura
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SourceSuite tests the Source implementations and loading values from sources.
+type SourceSuite struct {
+	suite.Suite
+}
+
+func (s *SourceSuite) TestEnvironment() {
+	s.T().Setenv("SOURCE_SUITE_ENV", "value")
+
+	value, ok := Environment.Lookup("SOURCE_SUITE_ENV")
+	s.True(ok)
+	s.Equal("value", value)
+	s.Equal("environment", Environment.Name())
+
+	_, ok = Environment.Lookup("SOURCE_SUITE_ENV_UNSET")
+	s.False(ok)
+}
+
+func (s *SourceSuite) TestMapSource() {
+	values := map[string]string{"KEY": "value"}
+	src := MapSource("defaults", values)
+	values["KEY"] = "changed"
+
+	value, ok := src.Lookup("KEY")
+	s.True(ok)
+	s.Equal("value", value, "MapSource should copy the provided map")
+	s.Equal("defaults", src.Name())
+
+	_, ok = src.Lookup("MISSING")
+	s.False(ok)
+}
+
+func (s *SourceSuite) TestSourceFunc() {
+	src := SourceFunc("upper", func(key string) (string, bool) {
+		return key + "_VALUE", key != "MISSING"
+	})
+
+	value, ok := src.Lookup("KEY")
+	s.True(ok)
+	s.Equal("KEY_VALUE", value)
+	_, ok = src.Lookup("MISSING")
+	s.False(ok)
+	s.Equal("upper", src.Name())
+}
+
+func (s *SourceSuite) TestDefaultSources() {
+	cfg := NewConfigImpl()
+	s.Equal([]Source{Environment}, cfg.Sources())
+}
+
+func (s *SourceSuite) TestLayeredPrecedence() {
+	s.T().Setenv("LAYERED_PORT", "9000")
+	s.T().Setenv("LAYERED_HOST", "env-host")
+
+	overrides := MapSource("overrides", map[string]string{"LAYERED_PORT": "8080"})
+	defaults := MapSource("defaults", map[string]string{"LAYERED_HOST": "default-host", "LAYERED_DEBUG": "true"})
+
+	cfg := NewConfigImpl()
+	cfg.SetSources(overrides, Environment, defaults)
+
+	port := Variable[int]("LAYERED_PORT")
+	host := Variable[string]("LAYERED_HOST")
+	debug := Variable[bool]("LAYERED_DEBUG")
+	timeout := Variable[int64]("LAYERED_TIMEOUT")
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, port, 3000),
+		LoadEnvironment(cfg, host, "localhost"),
+		LoadEnvironment(cfg, debug, false),
+		LoadEnvironment(cfg, timeout, 30),
+	))
+
+	s.Equal(8080, cfg.Int(port))
+	s.Equal("overrides", cfg.Origin(port))
+	s.Equal("env-host", cfg.String(host))
+	s.Equal("environment", cfg.Origin(host))
+	s.True(cfg.Bool(debug))
+	s.Equal("defaults", cfg.Origin(debug))
+	s.Equal(int64(30), cfg.Int64(timeout))
+	s.Equal(OriginFallback, cfg.Origin(timeout))
+}
+
+func (s *SourceSuite) TestInvalidValueDoesNotFallThrough() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(
+		MapSource("overrides", map[string]string{"PORT": "80a"}),
+		MapSource("defaults", map[string]string{"PORT": "8080"}),
+	)
+
+	err := LoadEnvironment(cfg, Variable[int]("PORT"), 3000)
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("overrides", parseErr.Source)
+	s.Contains(err.Error(), "from overrides")
+	s.Equal(3000, cfg.Int("PORT"))
+	s.Equal(OriginFallback, cfg.Origin(Variable[int]("PORT")))
+}
+
+func (s *SourceSuite) TestNoSources() {
+	s.T().Setenv("NO_SOURCES_KEY", "env")
+
+	cfg := NewConfigImpl()
+	cfg.SetSources()
+	s.Require().NoError(LoadEnvironment(cfg, Variable[string]("NO_SOURCES_KEY"), "fallback"))
+	s.Equal("fallback", cfg.String("NO_SOURCES_KEY"))
+}
+
+func (s *SourceSuite) TestOrigin() {
+	cfg := NewConfigImpl()
+	cfg.SetSources(MapSource("map", map[string]string{"KEY": "value"}))
+	key := Variable[string]("KEY")
+
+	s.Equal("", cfg.Origin(key), "unregistered variables have no origin")
+
+	s.Require().NoError(LoadEnvironment(cfg, key, ""))
+	s.Equal("map", cfg.Origin(key))
+	s.Equal("", cfg.Origin(Variable[int]("KEY")), "variables of another type have their own origin")
+
+	s.Require().NoError(WriteConfiguration(cfg, map[Variable[string]]string{key: "written"}))
+	s.Equal("", cfg.Origin(key), "written variables have no origin")
+
+	merged := Merge(cfg).(*ConfigImpl)
+	s.Require().NoError(LoadEnvironment(cfg, key, ""))
+	merged = Merge(merged, cfg).(*ConfigImpl)
+	s.Equal("map", merged.Origin(key))
+}
+
+func TestSourceSuite(t *testing.T) {
+	suite.Run(t, new(SourceSuite))
+}
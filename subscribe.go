@@ -0,0 +1,91 @@
+package configura
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Change describes a change of the value of a configuration variable, see Subscribe.
+type Change[T constraint] struct {
+	Key Variable[T]
+	Old T // Value before the change, the zero value if the variable wasn't registered.
+	New T // Value after the change.
+}
+
+// Subscribe registers fn to be called whenever the value of the configuration variable key changes, until the
+// returned function is called. Changes made by Reload, LoadEnvironment, WriteConfiguration and flags are all
+// reported. fn is called after the new configuration is published, so it may read or change the configuration itself.
+//
+// Changes are reported one at a time, in the order they were made. fn is usually called on the goroutine that made
+// the change, but a change made while the subscribers are being notified of another one, e.g. concurrently or by fn
+// itself, is reported by the goroutine notifying them once it is done, possibly after the change has returned.
+func Subscribe[T constraint](config *ConfigImpl, key Variable[T], fn func(Change[T])) (cancel func()) {
+	return register(&config.listenMu, &config.subscribers, func(prev, next *registry) {
+		old, hadOld := valueOf(prev, key)
+		value, hasValue := valueOf(next, key)
+		if hadOld != hasValue || !equal(old, value) {
+			fn(Change[T]{Key: key, Old: old, New: value})
+		}
+	})
+}
+
+// Notify sends the changes of the configuration variable key to ch, until the returned function is called. Like
+// signal.Notify, it doesn't block on ch: a change is dropped if ch isn't ready to receive it, so ch should be
+// buffered.
+func Notify[T constraint](config *ConfigImpl, key Variable[T], ch chan<- Change[T]) (cancel func()) {
+	return Subscribe(config, key, func(change Change[T]) {
+		select {
+		case ch <- change:
+		default:
+		}
+	})
+}
+
+// subscriber is called with the previous and the new snapshot whenever a configuration changes.
+type subscriber func(prev, next *registry)
+
+// notify calls the subscribers of the configuration with the previous and the new snapshot.
+func (c *ConfigImpl) notify(prev, next *registry) {
+	for _, fn := range registered(&c.listenMu, &c.subscribers) {
+		(*fn)(prev, next)
+	}
+}
+
+// register adds fn to the list guarded by mu, and returns a function removing it again. The list is replaced rather
+// than modified, so the slices returned by registered can be iterated without holding mu.
+func register[F any](mu *sync.Mutex, list *[]*F, fn F) (cancel func()) {
+	entry := &fn
+	mu.Lock()
+	*list = append(slices.Clip(*list), entry)
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		*list = slices.DeleteFunc(slices.Clone(*list), func(e *F) bool { return e == entry })
+	}
+}
+
+// registered returns the entries of the list guarded by mu.
+func registered[F any](mu *sync.Mutex, list *[]*F) []*F {
+	mu.Lock()
+	defer mu.Unlock()
+	return *list
+}
+
+// equal reports whether two values of a configuration variable are equal. Slices and maps are compared by content,
+// and empty ones are equal to nil. Pointers, like *regexp.Regexp, are compared by the value they point to, and so are
+// other types that aren't comparable.
+func equal[T constraint](a, b T) bool {
+	t := reflect.TypeFor[T]()
+	switch {
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Map:
+		va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+		return va.Len() == 0 && vb.Len() == 0 || reflect.DeepEqual(a, b)
+	case t.Kind() != reflect.Pointer && t.Comparable():
+		return any(a) == any(b)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
@@ -0,0 +1,133 @@
+package configura
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SubscribeSuite tests subscribing to changes of configuration variables.
+type SubscribeSuite struct {
+	suite.Suite
+	values map[string]string
+	config *ConfigImpl
+}
+
+func (s *SubscribeSuite) SetupTest() {
+	s.values = map[string]string{"PORT": "8080", "TOKEN": "abc"}
+	s.config = NewConfigImpl()
+	s.config.SetSources(SourceFunc("test", func(key string) (string, bool) {
+		v, ok := s.values[key]
+		return v, ok
+	}))
+}
+
+func (s *SubscribeSuite) TestSubscribe() {
+	var changes []Change[int]
+	cancel := Subscribe(s.config, Variable[int]("PORT"), func(change Change[int]) {
+		changes = append(changes, change)
+	})
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Require().NoError(s.config.Reload())
+	s.values["PORT"] = "9090"
+	s.Require().NoError(s.config.Reload())
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("PORT"), ""))
+	cancel()
+	s.values["PORT"] = "7070"
+	s.Require().NoError(s.config.Reload())
+
+	s.Equal([]Change[int]{
+		{Key: "PORT", Old: 0, New: 8080},
+		{Key: "PORT", Old: 8080, New: 9090},
+	}, changes, "only changes of the subscribed variable are reported")
+}
+
+func (s *SubscribeSuite) TestSliceValues() {
+	var changes []Change[[]byte]
+	defer Subscribe(s.config, Variable[[]byte]("TOKEN"), func(change Change[[]byte]) {
+		changes = append(changes, change)
+	})()
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[[]byte]("TOKEN"), nil))
+	s.Require().NoError(s.config.Reload())
+	s.values["TOKEN"] = "def"
+	s.Require().NoError(s.config.Reload())
+
+	s.Require().Len(changes, 2)
+	s.Equal([]byte("abc"), changes[1].Old)
+	s.Equal([]byte("def"), changes[1].New)
+}
+
+func (s *SubscribeSuite) TestWriteConfiguration() {
+	var changes []Change[string]
+	defer Subscribe(s.config, Variable[string]("MODE"), func(change Change[string]) {
+		changes = append(changes, change)
+	})()
+
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{"MODE": "fast"}))
+	s.Require().NoError(WriteConfiguration(s.config, map[Variable[string]]string{"OTHER": "x"}))
+
+	s.Equal([]Change[string]{
+		{Key: "MODE", Old: "", New: "fast"},
+		{Key: "MODE", Old: "fast", New: ""},
+	}, changes)
+}
+
+func (s *SubscribeSuite) TestCallbackMayChangeConfiguration() {
+	defer Subscribe(s.config, Variable[int]("PORT"), func(change Change[int]) {
+		s.NoError(WriteConfiguration(s.config, map[Variable[int64]]int64{"DERIVED": int64(change.New) + 1}))
+	})()
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.Equal(int64(8081), s.config.Int64("DERIVED"))
+}
+
+func (s *SubscribeSuite) TestConcurrentChangesInOrder() {
+	var mu sync.Mutex
+	var changes []Change[int]
+	defer Subscribe(s.config, Variable[int]("COUNTER"), func(change Change[int]) {
+		time.Sleep(100 * time.Microsecond)
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, change)
+	})()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.NoError(WriteConfiguration(s.config, map[Variable[int]]int{"COUNTER": i + 1}))
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Require().NotEmpty(changes)
+	for i := 1; i < len(changes); i++ {
+		s.Equal(changes[i-1].New, changes[i].Old, "change %d is reported after the one it replaced", i)
+	}
+	s.Equal(s.config.Int("COUNTER"), changes[len(changes)-1].New)
+}
+
+func (s *SubscribeSuite) TestNotify() {
+	ch := make(chan Change[int], 1)
+	cancel := Notify(s.config, Variable[int]("PORT"), ch)
+	defer cancel()
+
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 80))
+	s.values["PORT"] = "9090"
+	s.Require().NoError(s.config.Reload(), "a full channel must not block the reload")
+
+	s.Equal(Change[int]{Key: "PORT", Old: 0, New: 8080}, <-ch)
+	s.Empty(ch)
+	s.Equal(9090, s.config.Int("PORT"))
+}
+
+func TestSubscribeSuite(t *testing.T) {
+	suite.Run(t, new(SubscribeSuite))
+}
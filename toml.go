@@ -0,0 +1,70 @@
+package configura
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOMLFile reads the TOML document at path and returns a Source serving its values, named after the path. See
+// ParseTOML for how the document is mapped to configuration variables.
+func TOMLFile(path, separator string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, err := ParseTOML(path, f, separator)
+	if err != nil {
+		return nil, err
+	}
+	return fileSource{Source: src, path: path, read: func() (Source, error) { return TOMLFile(path, separator) }}, nil
+}
+
+// LoadTOML reads the TOML document at path and adds it as the source with the lowest precedence to the
+// configuration, see LoadDotenv.
+func LoadTOML(config *ConfigImpl, path, separator string) error {
+	src, err := TOMLFile(path, separator)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
+
+// ParseTOML parses the TOML document read from r, and returns a Source named name serving its values. Tables are
+// flattened by joining the path of a value with the separator, which defaults to an underscore when empty: with the
+// separator "__", the key url of the table [database] is served as DATABASE__URL. Keys are matched
+// case-insensitively, and dots and dashes are treated as underscores. Array elements are served by index.
+//
+// Integers are checked against the width of the configuration variable they are loaded into, so 300 can't be loaded
+// into a Variable[uint8] or Variable[int8]. Offset datetimes, local datetimes, local dates and local times load into
+// time.Time variables; local values are interpreted in the local time zone. Values that can't be stored are
+// reported by LoadEnvironment as a *ParseError, and syntax errors as a *SyntaxError.
+func ParseTOML(name string, r io.Reader, separator string) (Source, error) {
+	if separator == "" {
+		separator = "_"
+	}
+
+	var document map[string]any
+	if _, err := toml.NewDecoder(r).Decode(&document); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &SyntaxError{
+				Source: name,
+				Line:   parseErr.Position.Line,
+				Column: parseErr.Position.Col,
+				Msg:    parseErr.Message,
+			}
+		}
+		return nil, err
+	}
+
+	values := make(map[string]documentValue)
+	if err := flatten(name, values, nil, separator, document); err != nil {
+		return nil, err
+	}
+	return documentSource{name: name, values: values}, nil
+}
@@ -0,0 +1,238 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TOMLSuite tests loading configuration from TOML documents.
+type TOMLSuite struct {
+	suite.Suite
+}
+
+const tomlDocument = `name = "service"
+port = 0x1F90
+small = 300
+negative = -129
+ratio = 0.5
+debug = true
+started = 2024-05-01T12:30:00+02:00
+local_started = 2024-05-01T12:30:00
+release = 2024-05-01
+daily = 07:15:00
+brokers = ["kafka-1:9092", "kafka-2:9092"]
+
+[database]
+url = "postgres://localhost/db"
+pool = 10
+
+[database.replica]
+url = "postgres://replica/db"
+
+[[servers]]
+host = "alpha"
+
+[[servers]]
+host = "beta"
+`
+
+func (s *TOMLSuite) load(document, separator string) *ConfigImpl {
+	src, err := ParseTOML("config.toml", strings.NewReader(document), separator)
+	s.Require().NoError(err)
+
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+	return cfg
+}
+
+func (s *TOMLSuite) TestValues() {
+	cfg := s.load(tomlDocument, "")
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[string]("NAME"), ""),
+		LoadEnvironment(cfg, Variable[uint16]("PORT"), 0),
+		LoadEnvironment(cfg, Variable[int16]("SMALL"), 0),
+		LoadEnvironment(cfg, Variable[float64]("RATIO"), 0),
+		LoadEnvironment(cfg, Variable[bool]("DEBUG"), false),
+		LoadEnvironment(cfg, Variable[string]("BROKERS_1"), ""),
+		LoadEnvironment(cfg, Variable[string]("DATABASE_URL"), ""),
+		LoadEnvironment(cfg, Variable[int]("database.pool"), 0),
+		LoadEnvironment(cfg, Variable[string]("DATABASE_REPLICA_URL"), ""),
+		LoadEnvironment(cfg, Variable[string]("SERVERS_1_HOST"), ""),
+	))
+
+	s.Equal("service", cfg.String("NAME"))
+	s.Equal(uint16(8080), cfg.Uint16("PORT"))
+	s.Equal(int16(300), cfg.Int16("SMALL"))
+	s.Equal(0.5, cfg.Float64("RATIO"))
+	s.True(cfg.Bool("DEBUG"))
+	s.Equal("kafka-2:9092", cfg.String("BROKERS_1"))
+	s.Equal("postgres://localhost/db", cfg.String("DATABASE_URL"))
+	s.Equal(10, cfg.Int("database.pool"))
+	s.Equal("postgres://replica/db", cfg.String("DATABASE_REPLICA_URL"))
+	s.Equal("beta", cfg.String("SERVERS_1_HOST"))
+}
+
+func (s *TOMLSuite) TestSeparator() {
+	cfg := s.load(tomlDocument, "__")
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[string]("DATABASE__REPLICA__URL"), ""),
+		LoadEnvironment(cfg, Variable[string]("SERVERS__0__HOST"), ""),
+	))
+	s.Equal("postgres://replica/db", cfg.String("DATABASE__REPLICA__URL"))
+	s.Equal("alpha", cfg.String("SERVERS__0__HOST"))
+
+	s.NoError(LoadEnvironment(cfg, Variable[string]("DATABASE_URL"), "fallback"))
+	s.Equal("fallback", cfg.String("DATABASE_URL"), "keys are only joined with the configured separator")
+}
+
+func (s *TOMLSuite) TestSeparatorArrays() {
+	cfg := s.load(tomlDocument, "__")
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]string]("BROKERS"), nil))
+	s.Equal([]string{"kafka-1:9092", "kafka-2:9092"}, Get(cfg, Variable[[]string]("BROKERS")))
+	s.Equal("config.toml", cfg.Origin(Variable[[]string]("BROKERS")))
+
+	s.Require().NoError(LoadEnvironment(cfg, Variable[map[string]string]("SERVERS__1"), nil))
+	s.Equal(map[string]string{"host": "beta"}, Get(cfg, Variable[map[string]string]("SERVERS__1")))
+
+	err := LoadEnvironment(cfg, Variable[[]string]("SERVERS"), nil)
+	s.ErrorIs(err, ErrTypeMismatch, "the tables of an array are found, and can't be loaded as strings")
+	var parseErr *ParseError
+	s.Require().ErrorAs(err, &parseErr)
+	s.Equal("SERVERS__0", parseErr.Key)
+}
+
+func (s *TOMLSuite) TestDatetimes() {
+	cfg := s.load(tomlDocument, "")
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[time.Time]("STARTED"), time.Time{}),
+		LoadEnvironment(cfg, Variable[time.Time]("LOCAL_STARTED"), time.Time{}),
+		LoadEnvironment(cfg, Variable[time.Time]("RELEASE"), time.Time{}),
+		LoadEnvironment(cfg, Variable[time.Time]("DAILY"), time.Time{}),
+		LoadEnvironment(cfg, Variable[string]("STARTED"), ""),
+	))
+
+	s.True(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC).Equal(cfg.Time("STARTED")))
+	s.Equal(time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local), cfg.Time("LOCAL_STARTED"))
+	s.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), cfg.Time("RELEASE"))
+	s.Equal(time.Date(0, 1, 1, 7, 15, 0, 0, time.Local), cfg.Time("DAILY"))
+	s.Equal("2024-05-01T12:30:00+02:00", cfg.String("STARTED"))
+
+	err := LoadEnvironment(cfg, Variable[int]("STARTED"), 0)
+	s.ErrorIs(err, ErrTypeMismatch)
+}
+
+func (s *TOMLSuite) TestIntegerWidth() {
+	cfg := s.load(tomlDocument, "")
+
+	testCases := []struct {
+		name string
+		load func() error
+	}{
+		{"Uint8", func() error { return LoadEnvironment(cfg, Variable[uint8]("SMALL"), 0) }},
+		{"Int8", func() error { return LoadEnvironment(cfg, Variable[int8]("SMALL"), 0) }},
+		{"NegativeInt8", func() error { return LoadEnvironment(cfg, Variable[int8]("NEGATIVE"), 0) }},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			err := tc.load()
+			var parseErr *ParseError
+			s.Require().ErrorAs(err, &parseErr)
+			s.Equal("config.toml", parseErr.Source)
+			s.ErrorIs(err, strconv.ErrRange)
+		})
+	}
+
+	s.Run("Negative", func() {
+		err := LoadEnvironment(cfg, Variable[uint]("NEGATIVE"), 0)
+		s.ErrorIs(err, strconv.ErrSyntax)
+	})
+
+	s.Run("Floats", func() {
+		cfg := s.load("whole = 1.0\nexponent = 1e3\nratio = 0.5\ntimeout = 1.5\n", "")
+		for _, key := range []Variable[int]{"WHOLE", "EXPONENT"} {
+			err := LoadEnvironment(cfg, key, 7)
+			s.ErrorIs(err, ErrTypeMismatch, key)
+			s.ErrorContains(err, "type mismatch: float value")
+			s.Equal(7, cfg.Int(key))
+		}
+		s.ErrorIs(LoadEnvironment(cfg, Variable[uint64]("WHOLE"), 0), ErrTypeMismatch)
+
+		s.Require().NoError(errors.Join(
+			LoadEnvironment(cfg, Variable[float64]("EXPONENT"), 0),
+			LoadEnvironment(cfg, Variable[float32]("RATIO"), 0),
+			LoadEnvironment(cfg, Variable[time.Duration]("TIMEOUT"), 0, DurationUnit(time.Second)),
+			LoadEnvironment(cfg, Variable[string]("WHOLE"), ""),
+		))
+		s.Equal(1000.0, cfg.Float64("EXPONENT"))
+		s.Equal(float32(0.5), cfg.Float32("RATIO"))
+		s.Equal(1500*time.Millisecond, cfg.Duration("TIMEOUT"))
+		s.Equal("1", cfg.String("WHOLE"))
+	})
+
+	s.Run("Message", func() {
+		err := LoadEnvironment(cfg, Variable[int8]("SMALL"), 0)
+		s.EqualError(err, `invalid value "300" for configuration variable SMALL of type int8 from config.toml: value out of range, must be between -128 and 127`)
+	})
+}
+
+func (s *TOMLSuite) TestSyntaxErrors() {
+	testCases := []struct {
+		name     string
+		document string
+		line     int
+	}{
+		{"MissingValue", "name =\n", 1},
+		{"DuplicateKey", "a = 1\na = 2\n", 2},
+		{"UnterminatedString", "a = 1\nb = \"open\n", 2},
+		{"IntegerOverflow", "a = 99999999999999999999\n", 1},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := ParseTOML("config.toml", strings.NewReader(tc.document), "")
+			var syntaxErr *SyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.Equal("config.toml", syntaxErr.Source)
+			s.Equal(tc.line, syntaxErr.Line)
+		})
+	}
+}
+
+func (s *TOMLSuite) TestCollidingKeys() {
+	_, err := ParseTOML("config.toml", strings.NewReader("database_url = \"b\"\n[database]\nurl = \"a\"\n"), "")
+	var syntaxErr *SyntaxError
+	s.Require().ErrorAs(err, &syntaxErr)
+	s.EqualError(err, `config.toml: "database.url" and "database_url" are both loaded as DATABASE_URL`)
+
+	_, err = ParseTOML("config.toml", strings.NewReader("database_url = \"b\"\n[database]\nurl = \"a\"\n"), "__")
+	s.NoError(err, "the keys differ with another separator")
+}
+
+func (s *TOMLSuite) TestTOMLFile() {
+	path := filepath.Join(s.T().TempDir(), "config.toml")
+	s.Require().NoError(os.WriteFile(path, []byte("[toml_file]\nport = 9090\n"), 0o600))
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadTOML(cfg, path, ""))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("TOML_FILE_PORT"), 0))
+	s.Equal(9090, cfg.Int("TOML_FILE_PORT"))
+	s.Equal(path, cfg.Origin(Variable[int]("TOML_FILE_PORT")))
+
+	s.ErrorIs(LoadTOML(cfg, filepath.Join(s.T().TempDir(), "missing.toml"), ""), os.ErrNotExist)
+}
+
+func TestTOMLSuite(t *testing.T) {
+	suite.Run(t, new(TOMLSuite))
+}
@@ -0,0 +1,154 @@
+package configura
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watch polls the files and directories backing the sources of the configuration every interval, and reloads the
+// configuration when they change, until the returned function is called. Polling works on every platform and file
+// system, without relying on file system notifications.
+//
+// Changes are debounced: the reload only happens once the files haven't changed for the debounce duration, so a
+// burst of writes causes a single reload. Since reloads are all or nothing, a half-written file that doesn't parse
+// never replaces the live configuration; it is picked up once it is complete. The outcome of every reload is reported
+// to the listeners registered with OnReload, with the path of the changed file as the trigger.
+//
+// Sources added after Watch is called are watched as well. An error is returned, and nothing is watched, if interval
+// isn't positive.
+func (c *ConfigImpl) Watch(interval, debounce time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval must be positive, got %v", interval)
+	}
+
+	fingerprints := make(map[string]string)
+	for _, path := range watchedPaths(c.load().sources) {
+		fingerprints[path] = fingerprint(path)
+	}
+
+	done := make(chan struct{})
+	go c.watch(interval, debounce, fingerprints, done)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}, nil
+}
+
+// watch polls the watched paths until done is closed, starting from their fingerprints when Watch was called.
+func (c *ConfigImpl) watch(interval, debounce time.Duration, fingerprints map[string]string, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var trigger string
+	var deadline time.Time
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			for _, path := range watchedPaths(c.load().sources) {
+				current := fingerprint(path)
+				previous, seen := fingerprints[path]
+				fingerprints[path] = current
+				if seen && current != previous {
+					trigger, deadline = path, now.Add(debounce)
+				}
+			}
+			if trigger != "" && !now.Before(deadline) {
+				_ = c.reload(trigger)
+				trigger = ""
+			}
+		}
+	}
+}
+
+// watcher is implemented by sources backed by files or directories, reporting the paths to watch for changes.
+type watcher interface {
+	watchedPaths() []string
+}
+
+func (s fileSource) watchedPaths() []string {
+	return []string{s.path}
+}
+
+// watchedPaths returns the paths backing the wrapped source, and the files named by its keys with SecretFileSuffix
+// whose values are read from them.
+func (s secretFileSource) watchedPaths() []string {
+	paths := watchedPaths([]Source{s.source})
+	lister, ok := s.source.(keyLister)
+	if !ok {
+		return paths
+	}
+	for _, key := range lister.keys() {
+		name, ok := strings.CutSuffix(key, SecretFileSuffix)
+		if !ok || name == "" {
+			continue
+		}
+		if _, ok := s.source.Lookup(name); ok {
+			continue
+		}
+		if path, ok := s.source.Lookup(key); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// watchedPaths returns the paths backing the sources.
+func watchedPaths(sources []Source) []string {
+	var paths []string
+	for _, source := range sources {
+		if w, ok := source.(watcher); ok {
+			paths = append(paths, w.watchedPaths()...)
+		}
+	}
+	return paths
+}
+
+// fingerprint summarizes the content of a file, or of the files of a directory, so that any change to them changes
+// the fingerprint. Following the layout of Kubernetes mounts, the entries of a directory are read
+// through its ..data symlink if it has one, see DirectorySource.
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err.Error()
+	}
+	if !info.IsDir() {
+		return fileFingerprint(path)
+	}
+
+	dir := path
+	if target, err := filepath.EvalSymlinks(filepath.Join(path, kubernetesDataDir)); err == nil {
+		dir = target
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err.Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(dir)
+	for _, entry := range entries {
+		b.WriteString("\n" + entry.Name() + " " + fileFingerprint(filepath.Join(dir, entry.Name())))
+	}
+	return b.String()
+}
+
+// fileFingerprint hashes the content of a file. Configuration files are small, and unlike modification times,
+// hashes catch every change regardless of the resolution of the file system's timestamps.
+func fileFingerprint(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err.Error()
+	}
+	h := fnv.New64a()
+	h.Write(content)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,183 @@
+package configura
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// WatchSuite tests reloading the configuration when the files backing its sources change.
+type WatchSuite struct {
+	suite.Suite
+	dir    string
+	config *ConfigImpl
+	events chan ReloadEvent
+}
+
+const (
+	watchInterval = 5 * time.Millisecond
+	watchDebounce = 50 * time.Millisecond
+	watchTimeout  = 5 * time.Second
+)
+
+func (s *WatchSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.config = NewConfigImpl()
+	s.config.SetSources()
+	s.events = make(chan ReloadEvent, 16)
+	s.T().Cleanup(s.config.OnReload(func(event ReloadEvent) { s.events <- event }))
+}
+
+func (s *WatchSuite) write(path, content string) {
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+}
+
+func (s *WatchSuite) awaitReload() ReloadEvent {
+	select {
+	case event := <-s.events:
+		return event
+	case <-time.After(watchTimeout):
+		s.FailNow("no reload")
+		return ReloadEvent{}
+	}
+}
+
+func (s *WatchSuite) TestFileChange() {
+	path := filepath.Join(s.dir, "config.yaml")
+	s.write(path, "port: 8080\n")
+	s.Require().NoError(LoadYAML(s.config, path))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 0))
+
+	stop, err := s.config.Watch(watchInterval, watchDebounce)
+	s.Require().NoError(err)
+	defer stop()
+
+	s.write(path, "port: 9090\n")
+	event := s.awaitReload()
+	s.Equal(path, event.Trigger)
+	s.NoError(event.Err)
+	s.Equal([]string{"PORT"}, event.Changed)
+	s.Equal(9090, s.config.Int("PORT"))
+}
+
+func (s *WatchSuite) TestHalfWrittenFile() {
+	path := filepath.Join(s.dir, "config.json")
+	s.write(path, `{"port": 8080, "name": "service"}`)
+	s.Require().NoError(LoadJSON(s.config, path))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 0))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("NAME"), ""))
+
+	stop, err := s.config.Watch(watchInterval, watchDebounce)
+	s.Require().NoError(err)
+	defer stop()
+
+	s.write(path, `{"port": 9090, "na`)
+	event := s.awaitReload()
+	var syntaxErr *SyntaxError
+	s.ErrorAs(event.Err, &syntaxErr)
+	s.Equal(8080, s.config.Int("PORT"), "a file that doesn't parse must not replace the configuration")
+
+	s.write(path, `{"port": 9090, "name": "renamed"}`)
+	event = s.awaitReload()
+	s.NoError(event.Err)
+	s.Equal(9090, s.config.Int("PORT"))
+	s.Equal("renamed", s.config.String("NAME"))
+}
+
+func (s *WatchSuite) TestDebounce() {
+	path := filepath.Join(s.dir, ".env")
+	s.write(path, "COUNT=0\n")
+	s.Require().NoError(LoadDotenv(s.config, path))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("COUNT"), -1))
+
+	stop, err := s.config.Watch(watchInterval, 200*time.Millisecond)
+	s.Require().NoError(err)
+	defer stop()
+
+	for _, count := range []string{"1", "2", "3", "4", "5"} {
+		s.write(path, "COUNT="+count+"\n")
+		time.Sleep(2 * watchInterval)
+	}
+	event := s.awaitReload()
+	s.NoError(event.Err)
+	s.Equal(5, s.config.Int("COUNT"))
+
+	select {
+	case event := <-s.events:
+		s.Failf("unexpected reload", "a burst of writes must cause a single reload, got %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func (s *WatchSuite) TestKubernetesDirectory() {
+	mount := filepath.Join(s.dir, "mount")
+	s.Require().NoError(os.MkdirAll(filepath.Join(mount, "..v1"), 0o700))
+	s.write(filepath.Join(mount, "..v1", "LOG_LEVEL"), "info")
+	s.Require().NoError(os.Symlink("..v1", filepath.Join(mount, kubernetesDataDir)))
+	s.Require().NoError(os.Symlink(filepath.Join(kubernetesDataDir, "LOG_LEVEL"), filepath.Join(mount, "LOG_LEVEL")))
+
+	s.Require().NoError(LoadDirectory(s.config, mount, true))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("LOG_LEVEL"), ""))
+	stop, err := s.config.Watch(watchInterval, watchDebounce)
+	s.Require().NoError(err)
+	defer stop()
+
+	s.Require().NoError(os.MkdirAll(filepath.Join(mount, "..v2"), 0o700))
+	s.write(filepath.Join(mount, "..v2", "LOG_LEVEL"), "debug")
+	s.Require().NoError(os.Symlink("..v2", filepath.Join(mount, "..data_tmp")))
+	s.Require().NoError(os.Rename(filepath.Join(mount, "..data_tmp"), filepath.Join(mount, kubernetesDataDir)))
+
+	event := s.awaitReload()
+	s.Equal(mount, event.Trigger)
+	s.NoError(event.Err)
+	s.Equal("debug", s.config.String("LOG_LEVEL"))
+}
+
+func (s *WatchSuite) TestStop() {
+	path := filepath.Join(s.dir, "config.toml")
+	s.write(path, "port = 8080\n")
+	s.Require().NoError(LoadTOML(s.config, path, ""))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[int]("PORT"), 0))
+
+	stop, err := s.config.Watch(watchInterval, 0)
+	s.Require().NoError(err)
+	stop()
+	stop()
+
+	s.write(path, "port = 9090\n")
+	time.Sleep(10 * watchInterval)
+	s.Empty(s.events)
+	s.Equal(8080, s.config.Int("PORT"))
+}
+
+func (s *WatchSuite) TestSecretFile() {
+	path := filepath.Join(s.dir, "db_pw")
+	s.write(path, "s3cret")
+	s.config.SetSources(SecretFiles(MapSource("test", map[string]string{"DB_PASSWORD_FILE": path}), false))
+	s.Require().NoError(LoadEnvironment(s.config, Variable[string]("DB_PASSWORD"), ""))
+
+	stop, err := s.config.Watch(watchInterval, watchDebounce)
+	s.Require().NoError(err)
+	defer stop()
+
+	s.write(path, "rotated")
+	event := s.awaitReload()
+	s.Equal(path, event.Trigger)
+	s.NoError(event.Err)
+	s.Equal("rotated", s.config.String("DB_PASSWORD"))
+}
+
+func (s *WatchSuite) TestInvalidInterval() {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		stop, err := s.config.Watch(interval, watchDebounce)
+		s.EqualError(err, "watch interval must be positive, got "+interval.String())
+		s.Nil(stop)
+	}
+}
+
+func TestWatchSuite(t *testing.T) {
+	suite.Run(t, new(WatchSuite))
+}
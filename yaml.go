@@ -0,0 +1,265 @@
+package configura
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLFile reads the YAML document at path and returns a Source serving its values, named after the path. See
+// ParseYAML for how the document is mapped to configuration variables.
+func YAMLFile(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, err := ParseYAML(path, f)
+	if err != nil {
+		return nil, err
+	}
+	return fileSource{Source: src, path: path, read: func() (Source, error) { return YAMLFile(path) }}, nil
+}
+
+// LoadYAML reads the YAML document at path and adds it as the source with the lowest precedence to the
+// configuration, see LoadDotenv.
+func LoadYAML(config *ConfigImpl, path string) error {
+	src, err := YAMLFile(path)
+	if err != nil {
+		return err
+	}
+	config.AddSource(src)
+	return nil
+}
+
+// ParseYAML parses the YAML document read from r, and returns a Source named name serving its values. The document
+// must be a mapping, and is flattened the same way as JSON documents are, see ParseJSON: the value at the path
+// database.url is served as DATABASE_URL. Anchors, aliases and merge keys (<<) are resolved.
+//
+// Values that can't be stored in the configuration variable they are loaded into are reported by LoadEnvironment as
+// a *ParseError holding the line and column of the value. Syntax errors are reported as a *SyntaxError.
+func ParseYAML(name string, r io.Reader) (Source, error) {
+	var document yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return documentSource{name: name, values: map[string]documentValue{}}, nil
+		}
+		return nil, yamlError(name, err)
+	}
+
+	root := &document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	root = resolveAlias(root)
+	if root.Kind != yaml.MappingNode {
+		return nil, &SyntaxError{Source: name, Line: root.Line, Column: root.Column, Msg: "YAML document must be a mapping"}
+	}
+
+	w := &yamlWalker{name: name, values: make(map[string]documentValue)}
+	if err := w.walk(nil, root, 0); err != nil {
+		return nil, err
+	}
+	return documentSource{name: name, values: w.values}, nil
+}
+
+// yamlLinePattern extracts the line number from the errors of the YAML decoder, e.g. "yaml: line 3: found ...".
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlError converts the errors of the YAML decoder to a *SyntaxError if they report a position.
+func yamlError(name string, err error) error {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	line, _ := strconv.Atoi(match[1])
+	return &SyntaxError{Source: name, Line: line, Msg: match[2]}
+}
+
+// maxYAMLDepth bounds the nesting of YAML documents, protecting against aliases referring to themselves.
+const maxYAMLDepth = 100
+
+// maxYAMLNodes bounds the number of nodes of a YAML document once its aliases and merge keys are expanded, protecting
+// against documents that alias the same nodes over and over to expand exponentially.
+const maxYAMLNodes = 100_000
+
+// yamlWalker flattens the nodes of a YAML document into document values.
+type yamlWalker struct {
+	name   string
+	values map[string]documentValue
+	nodes  int // Number of nodes expanded so far, see maxYAMLNodes.
+}
+
+func (w *yamlWalker) walk(path []string, node *yaml.Node, depth int) error {
+	if depth > maxYAMLDepth {
+		return w.errorf(node, "YAML document nested too deeply")
+	}
+	if err := w.expand(node, 1); err != nil {
+		return err
+	}
+
+	key := normalizeKey(strings.Join(path, "_"))
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		pairs, err := w.pairs(node, depth)
+		if err != nil {
+			return err
+		}
+		fields := make(map[string]string, len(pairs))
+		for _, pair := range pairs {
+			nestedPath := append(path[:len(path):len(path)], pair[0].Value)
+			if err := w.walk(nestedPath, pair[1], depth+1); err != nil {
+				return err
+			}
+			if nestedKey := normalizeKey(strings.Join(nestedPath, "_")); w.values[nestedKey].kind != "" {
+				fields[pair[0].Value] = nestedKey
+			}
+		}
+		if len(path) > 0 {
+			value := documentValue{raw: yamlRaw(node), kind: kindObject, line: node.Line, column: node.Column, fields: fields}
+			return addValue(w.name, w.values, key, path, value)
+		}
+	case yaml.SequenceNode:
+		elements := make([]string, len(node.Content))
+		for i := range node.Content {
+			elements[i] = normalizeKey(strings.Join(append(path[:len(path):len(path)], strconv.Itoa(i)), "_"))
+		}
+		value := documentValue{raw: yamlRaw(node), kind: kindArray, line: node.Line, column: node.Column, elements: elements}
+		if err := addValue(w.name, w.values, key, path, value); err != nil {
+			return err
+		}
+		for i, item := range node.Content {
+			if err := w.walk(append(path[:len(path):len(path)], strconv.Itoa(i)), item, depth+1); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		value, ok, err := w.scalar(node)
+		if err != nil || !ok {
+			return err
+		}
+		return addValue(w.name, w.values, key, path, value)
+	}
+	return nil
+}
+
+// pairs returns the key and value nodes of a mapping, with the pairs of merge keys (<<) resolved. Keys of the
+// mapping itself take precedence over merged keys, and earlier merged mappings over later ones.
+func (w *yamlWalker) pairs(node *yaml.Node, depth int) ([][2]*yaml.Node, error) {
+	var pairs, merged [][2]*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := resolveAlias(node.Content[i]), node.Content[i+1]
+		if key.Kind != yaml.ScalarNode {
+			return nil, w.errorf(key, "YAML mapping keys must be scalars")
+		}
+		if key.Tag != "!!merge" {
+			pairs = append(pairs, [2]*yaml.Node{key, value})
+			continue
+		}
+
+		value = resolveAlias(value)
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			source = resolveAlias(source)
+			if source.Kind != yaml.MappingNode {
+				return nil, w.errorf(source, "YAML merge keys must refer to mappings")
+			}
+			if depth > maxYAMLDepth {
+				return nil, w.errorf(source, "YAML document nested too deeply")
+			}
+			nested, err := w.pairs(source, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := w.expand(source, len(nested)); err != nil {
+				return nil, err
+			}
+			merged = append(merged, nested...)
+		}
+	}
+
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		seen[pair[0].Value] = true
+	}
+	for _, pair := range merged {
+		if !seen[pair[0].Value] {
+			seen[pair[0].Value] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+// scalar converts a scalar node to a document value, normalizing numbers and booleans written in any of the forms
+// YAML allows. The boolean is false for null values.
+func (w *yamlWalker) scalar(node *yaml.Node) (documentValue, bool, error) {
+	value := documentValue{raw: node.Value, kind: kindString, line: node.Line, column: node.Column}
+	switch node.ShortTag() {
+	case "!!null":
+		return value, false, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return value, false, w.errorf(node, "%v", err)
+		}
+		value.raw, value.kind = strconv.FormatBool(b), kindBool
+	case "!!int":
+		var i int64
+		var u uint64
+		switch {
+		case node.Decode(&i) == nil:
+			value.raw = strconv.FormatInt(i, 10)
+		case node.Decode(&u) == nil:
+			value.raw = strconv.FormatUint(u, 10)
+		}
+		value.kind = kindNumber
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return value, false, w.errorf(node, "%v", err)
+		}
+		value.raw, value.kind = strconv.FormatFloat(f, 'g', -1, 64), kindFloat
+	}
+	return value, true, nil
+}
+
+// expand counts n more nodes expanded at node, and reports an error once there are more than maxYAMLNodes.
+func (w *yamlWalker) expand(node *yaml.Node, n int) error {
+	w.nodes += n
+	if w.nodes > maxYAMLNodes {
+		return w.errorf(node, "YAML document expands to more than %d nodes, check its aliases", maxYAMLNodes)
+	}
+	return nil
+}
+
+func (w *yamlWalker) errorf(node *yaml.Node, format string, args ...any) error {
+	return &SyntaxError{Source: w.name, Line: node.Line, Column: node.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// resolveAlias returns the node an alias refers to, or the node itself if it isn't an alias.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// yamlRaw returns the YAML representation of a mapping or sequence, used as its raw form in errors.
+func yamlRaw(node *yaml.Node) string {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
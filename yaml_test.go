@@ -0,0 +1,219 @@
+package configura
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// YAMLSuite tests loading configuration from YAML documents.
+type YAMLSuite struct {
+	suite.Suite
+}
+
+const yamlDocument = `defaults: &defaults
+  pool: 5
+  timeout: 30
+  ssl: yes_please
+
+database:
+  <<: *defaults
+  url: postgres://localhost/db
+  pool: 10
+
+replica:
+  <<: [*defaults]
+  url: &replica_url postgres://replica/db
+
+backup_url: *replica_url
+port: 0x1F90
+debug: true
+ratio: .5
+small: 300
+name: "service"
+nothing: ~
+brokers:
+  - kafka-1:9092
+  - kafka-2:9092
+`
+
+func (s *YAMLSuite) load(document string) *ConfigImpl {
+	src, err := ParseYAML("config.yaml", strings.NewReader(document))
+	s.Require().NoError(err)
+
+	cfg := NewConfigImpl()
+	cfg.SetSources(src)
+	return cfg
+}
+
+func (s *YAMLSuite) TestValues() {
+	cfg := s.load(yamlDocument)
+
+	s.Require().NoError(errors.Join(
+		LoadEnvironment(cfg, Variable[string]("DATABASE_URL"), ""),
+		LoadEnvironment(cfg, Variable[int]("DATABASE_POOL"), 0),
+		LoadEnvironment(cfg, Variable[int]("database.timeout"), 0),
+		LoadEnvironment(cfg, Variable[int]("REPLICA_POOL"), 0),
+		LoadEnvironment(cfg, Variable[string]("REPLICA_URL"), ""),
+		LoadEnvironment(cfg, Variable[string]("BACKUP_URL"), ""),
+		LoadEnvironment(cfg, Variable[uint16]("PORT"), 0),
+		LoadEnvironment(cfg, Variable[bool]("DEBUG"), false),
+		LoadEnvironment(cfg, Variable[float32]("RATIO"), 0),
+		LoadEnvironment(cfg, Variable[string]("NAME"), ""),
+		LoadEnvironment(cfg, Variable[string]("NOTHING"), "fallback"),
+		LoadEnvironment(cfg, Variable[string]("BROKERS_0"), ""),
+	))
+
+	s.Equal("postgres://localhost/db", cfg.String("DATABASE_URL"))
+	s.Equal(10, cfg.Int("DATABASE_POOL"), "keys of the mapping take precedence over merged keys")
+	s.Equal(30, cfg.Int("database.timeout"))
+	s.Equal(5, cfg.Int("REPLICA_POOL"))
+	s.Equal("postgres://replica/db", cfg.String("REPLICA_URL"))
+	s.Equal("postgres://replica/db", cfg.String("BACKUP_URL"))
+	s.Equal(uint16(8080), cfg.Uint16("PORT"))
+	s.True(cfg.Bool("DEBUG"))
+	s.Equal(float32(0.5), cfg.Float32("RATIO"))
+	s.Equal("service", cfg.String("NAME"))
+	s.Equal("fallback", cfg.String("NOTHING"))
+	s.Equal("kafka-1:9092", cfg.String("BROKERS_0"))
+}
+
+func (s *YAMLSuite) TestLoadErrorPositions() {
+	cfg := s.load(yamlDocument)
+
+	testCases := []struct {
+		name   string
+		load   func() error
+		line   int
+		column int
+		target error
+	}{
+		{"Overflow", func() error { return LoadEnvironment(cfg, Variable[uint8]("SMALL"), 0) }, 19, 8, strconv.ErrRange},
+		{"InvalidBool", func() error { return LoadEnvironment(cfg, Variable[bool]("DEFAULTS_SSL"), false) }, 4, 8, strconv.ErrSyntax},
+		{"MergedValue", func() error { return LoadEnvironment(cfg, Variable[bool]("DATABASE_TIMEOUT"), false) }, 3, 12, ErrTypeMismatch},
+		{"Mapping", func() error { return LoadEnvironment(cfg, Variable[string]("DATABASE"), "") }, 7, 3, ErrTypeMismatch},
+		{"Sequence", func() error { return LoadEnvironment(cfg, Variable[string]("BROKERS"), "") }, 23, 3, ErrTypeMismatch},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			err := tc.load()
+			var parseErr *ParseError
+			s.Require().ErrorAs(err, &parseErr)
+			s.Equal("config.yaml", parseErr.Source)
+			s.Equal(tc.line, parseErr.Line)
+			s.Equal(tc.column, parseErr.Column)
+			s.ErrorIs(err, tc.target)
+		})
+	}
+
+	s.Run("Message", func() {
+		err := LoadEnvironment(cfg, Variable[uint8]("SMALL"), 0)
+		s.EqualError(err, `invalid value "300" for configuration variable SMALL of type uint8 from config.yaml:19:8: value out of range, must be between 0 and 255`)
+	})
+}
+
+func (s *YAMLSuite) TestSyntaxErrors() {
+	testCases := []struct {
+		name     string
+		document string
+		line     int
+		column   int
+	}{
+		{"BadIndentation", "database:\n  url: a\n bad: b\n", 2, 0},
+		{"UnterminatedFlow", "a: [1, 2\n", 1, 0},
+		{"NotAMapping", "- a\n- b\n", 1, 1},
+		{"MergeScalar", "a: &a 1\nb:\n  <<: *a\n", 1, 4},
+		{"ComplexKey", "? [a, b]\n: value\n", 1, 3},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := ParseYAML("config.yaml", strings.NewReader(tc.document))
+			var syntaxErr *SyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.Equal("config.yaml", syntaxErr.Source)
+			s.Equal(tc.line, syntaxErr.Line)
+			s.Equal(tc.column, syntaxErr.Column)
+		})
+	}
+}
+
+func (s *YAMLSuite) TestFloats() {
+	cfg := s.load("whole: 1.0\nexponent: 1e3\n")
+	err := LoadEnvironment(cfg, Variable[int]("WHOLE"), 7)
+	s.ErrorIs(err, ErrTypeMismatch)
+	s.EqualError(err, `invalid value "1" for configuration variable WHOLE of type int from config.yaml:1:8: type mismatch: float value`)
+	s.Require().NoError(LoadEnvironment(cfg, Variable[float64]("EXPONENT"), 0))
+	s.Equal(1000.0, cfg.Float64("EXPONENT"))
+}
+
+func (s *YAMLSuite) TestCollidingKeys() {
+	_, err := ParseYAML("config.yaml", strings.NewReader("database:\n  url: a\ndatabase_url: b\n"))
+	var syntaxErr *SyntaxError
+	s.Require().ErrorAs(err, &syntaxErr)
+	s.EqualError(err, `config.yaml:3:15: "database.url" and "database_url" are both loaded as DATABASE_URL`)
+}
+
+func (s *YAMLSuite) TestUnknownAnchor() {
+	_, err := ParseYAML("config.yaml", strings.NewReader("a: 1\nb: *missing\n"))
+	s.EqualError(err, "config.yaml: yaml: unknown anchor 'missing' referenced")
+}
+
+func (s *YAMLSuite) TestAliasExpansion() {
+	aliased := func(item string) string {
+		return "[" + strings.TrimSuffix(strings.Repeat(item+",", 10), ",") + "]"
+	}
+	testCases := []struct {
+		name     string
+		document string
+	}{
+		{"Sequences", "a: &a " + aliased("1") + "\nb: &b " + aliased("*a") + "\nc: &c " + aliased("*b") +
+			"\nd: &d " + aliased("*c") + "\ne: &e " + aliased("*d") + "\nf: &f " + aliased("*e") + "\n"},
+		{"MergeKeys", "a: &a {k0: 0, k1: 1, k2: 2, k3: 3, k4: 4, k5: 5, k6: 6, k7: 7, k8: 8, k9: 9}\nb: &b {<<: " +
+			aliased("*a") + "}\nc: &c {<<: " + aliased("*b") + "}\nd: &d {<<: " + aliased("*c") + "}\ne: &e {<<: " +
+			aliased("*d") + "}\nf: &f {<<: " + aliased("*e") + "}\n"},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			_, err := ParseYAML("config.yaml", strings.NewReader(tc.document))
+			var syntaxErr *SyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.ErrorContains(err, "YAML document expands to more than 100000 nodes")
+		})
+	}
+
+	cfg := s.load("base: &base [1, 2]\ncopies: [*base, *base]\n")
+	s.Require().NoError(LoadEnvironment(cfg, Variable[[]int]("COPIES_1"), nil))
+	s.Equal([]int{1, 2}, Get(cfg, Variable[[]int]("COPIES_1")), "reasonable aliases are expanded")
+}
+
+func (s *YAMLSuite) TestEmptyDocument() {
+	src, err := ParseYAML("empty.yaml", strings.NewReader(""))
+	s.Require().NoError(err)
+	_, ok := src.Lookup("ANY")
+	s.False(ok)
+}
+
+func (s *YAMLSuite) TestYAMLFile() {
+	path := filepath.Join(s.T().TempDir(), "config.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte("yaml_file:\n  port: 9090\n"), 0o600))
+
+	cfg := NewConfigImpl()
+	s.Require().NoError(LoadYAML(cfg, path))
+	s.Require().NoError(LoadEnvironment(cfg, Variable[int]("YAML_FILE_PORT"), 0))
+	s.Equal(9090, cfg.Int("YAML_FILE_PORT"))
+	s.Equal(path, cfg.Origin(Variable[int]("YAML_FILE_PORT")))
+
+	s.ErrorIs(LoadYAML(cfg, filepath.Join(s.T().TempDir(), "missing.yaml")), os.ErrNotExist)
+}
+
+func TestYAMLSuite(t *testing.T) {
+	suite.Run(t, new(YAMLSuite))
+}